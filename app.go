@@ -6,48 +6,107 @@ import (
 	"io"
 	"log"
 	"os"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
-	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
-	"github.com/mashiike/rules2cron/internal/eventbridgex"
+	"time"
 )
 
 type App struct {
-	client    *eventbridge.Client
+	source    Source
 	converter *Converter
+	options   AppOptions
 }
 
-func New(ctx context.Context, converter *Converter) (*App, error) {
-	opts := make([]func(*config.LoadOptions) error, 0)
+// AppOptions is the options for App
+type AppOptions struct {
+	// From and To is the period to convert the schedules.
+	// If set, the schedules are split at the changes of the UTC offset in the period, such as the daylight saving time.
+	From time.Time
+	To   time.Time
+
+	// FailOnSkip makes RunWithContext return *SkippedRulesError when any rules could not be converted.
+	FailOnSkip bool
+
+	// Formatter writes the converted records, the default is the TSV for cronv.
+	Formatter Formatter
+
+	// Source is the source of the rules, the default is AWSSource with the default config.
+	Source Source
+
+	// EventBusNames is the event buses to list the rules with the default source, all the event buses if empty.
+	EventBusNames []string
+
+	// Regions, Profiles and RoleArns are the regions and the accounts to scan with the default source, see ScanTargets.
+	// If any of them is set, the rules are listed by ScanSource and tagged with the account and the region.
+	Regions  []string
+	Profiles []string
+	RoleArns []string
+
+	// Concurrency is the number of the targets scanned at the same time, the default is 4.
+	Concurrency int
 
-	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
-		opts = append(opts, config.WithRegion(region))
+	// Filter selects the rules. If States is set, it replaces showDisabled of the run methods.
+	Filter RuleFilter
+
+	// WithTargets attaches the targets of the rules to the records, listed with ListTargetsByRule with the default source.
+	WithTargets bool
+
+	// WithoutScheduler skips the schedules of EventBridge Scheduler with the default source.
+	WithoutScheduler bool
+
+	// Overrides overrides the rules before Filter, in order.
+	Overrides []RuleOverride
+}
+
+func New(ctx context.Context, converter *Converter, optFns ...func(*AppOptions)) (*App, error) {
+	var options AppOptions
+	for _, fn := range optFns {
+		fn(&options)
 	}
-	if endpoint := os.Getenv("EVENTBRIDGE_ENDPOINT"); endpoint != "" {
-		opts = append(opts, config.WithEndpointResolverWithOptions(
-			aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
-				if service == eventbridge.ServiceID {
-					return aws.Endpoint{
-						URL:           endpoint,
-						PartitionID:   "aws",
-						SigningRegion: region,
-					}, nil
-				}
-				return aws.Endpoint{}, &aws.EndpointNotFoundError{}
-			})))
+	if options.Formatter == nil {
+		options.Formatter = FormatterFunc(formatTSV)
 	}
-	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
-	if err != nil {
-		return nil, err
+	for i := range options.Overrides {
+		if err := options.Overrides[i].compile(); err != nil {
+			return nil, fmt.Errorf("overrides[%d]: %w", i, err)
+		}
 	}
-
 	app := &App{
-		client:    eventbridge.NewFromConfig(awsCfg),
+		source:    options.Source,
 		converter: converter,
+		options:   options,
+	}
+	if app.source != nil {
+		if len(options.Filter.Tags) > 0 {
+			return nil, fmt.Errorf("tag filters are available only with the rules of AWS")
+		}
+		return app, nil
 	}
-	return app, err
+	withoutScheduler := options.WithoutScheduler
+	if !withoutScheduler && os.Getenv("EVENTBRIDGE_ENDPOINT") != "" && os.Getenv("SCHEDULER_ENDPOINT") == "" {
+		log.Println("[warn] the schedules of EventBridge Scheduler are skipped, since EVENTBRIDGE_ENDPOINT is set without SCHEDULER_ENDPOINT")
+		withoutScheduler = true
+	}
+	awsSourceOptions := func(o *AWSSourceOptions) {
+		o.EventBusNames = options.EventBusNames
+		o.NamePrefix = options.Filter.NamePrefix
+		o.Tags = options.Filter.Tags
+		o.WithTargets = options.WithTargets
+		o.WithoutScheduler = withoutScheduler
+	}
+	if len(options.Regions)+len(options.Profiles)+len(options.RoleArns) > 0 {
+		targets := ScanTargets(options.Regions, options.Profiles, options.RoleArns)
+		src, err := NewScanSource(ctx, targets, options.Concurrency, awsSourceOptions)
+		if err != nil {
+			return nil, err
+		}
+		app.source = src
+		return app, nil
+	}
+	awsCfg, err := loadAWSConfig(ctx, ScanTarget{})
+	if err != nil {
+		return nil, err
+	}
+	app.source = NewAWSSource(awsCfg, awsSourceOptions)
+	return app, nil
 }
 
 func (app *App) Run(w io.Writer, showDisabled bool) error {
@@ -55,28 +114,281 @@ func (app *App) Run(w io.Writer, showDisabled bool) error {
 }
 
 func (app *App) RunWithContext(ctx context.Context, w io.Writer, showDisabled bool) error {
-	p := eventbridgex.NewListRulesPaginator(app.client, &eventbridge.ListRulesInput{})
-	for p.HasMorePages() {
-		output, err := p.NextPage(ctx)
+	records := make([]*Record, 0)
+	return app.convertEachRule(ctx, showDisabled, func(rule *Rule) error {
+		converted, err := app.convertRule(rule)
+		if err != nil {
+			return err
+		}
+		records = append(records, converted...)
+		return nil
+	}, func() error {
+		return app.options.Formatter.Format(w, records)
+	})
+}
+
+// RunNextWithContext writes the next n fire times after start for each rule.
+func (app *App) RunNextWithContext(ctx context.Context, w io.Writer, showDisabled bool, start time.Time, n int) error {
+	return app.eachRule(ctx, showDisabled, func(rule *Rule) error {
+		expr, err := ParseExpression(rule.ScheduleExpression)
+		if err != nil {
+			log.Printf("[warn] rule %s: %s", rule.Name, err.Error())
+			return nil
+		}
+		for _, t := range NextFireTimes(expr, start, rule.TimeZone, n) {
+			fmt.Fprintf(w, "%s\t%s\n", t.In(app.converter.TimeZone).Format(time.RFC3339), rule.Name)
+		}
+		return nil
+	})
+}
+
+// SystemdOptions is the options for RunSystemdWithContext.
+type SystemdOptions struct {
+	// Dir is the directory to write the units.
+	Dir string
+	// UnitPrefix is prefixed to the rule name to name the units.
+	UnitPrefix string
+	// ExecStart is the command of the service units.
+	ExecStart string
+}
+
+// RunSystemdWithContext writes the timer unit and the service unit of systemd for each rule.
+func (app *App) RunSystemdWithContext(ctx context.Context, showDisabled bool, opts SystemdOptions) error {
+	units := make([]*SystemdUnit, 0)
+	return app.convertEachRule(ctx, showDisabled, func(rule *Rule) error {
+		expr, err := ParseExpression(rule.ScheduleExpression)
+		if err != nil {
+			return err
+		}
+		spec, err := app.converter.ConvertToCalendarSpec(expr, rule.TimeZone)
 		if err != nil {
 			return err
 		}
-		for _, rule := range output.Rules {
-			if rule.ScheduleExpression == nil {
-				log.Printf("[debug] rule %s is not scheduled rule, skip", *rule.Arn)
-				continue
+		units = append(units, NewSystemdUnit(rule, spec, opts.UnitPrefix, opts.ExecStart))
+		return nil
+	}, func() error {
+		names := make(map[string]*Rule, len(units))
+		for _, unit := range units {
+			if other, ok := names[unit.Name]; ok {
+				return fmt.Errorf("unit %s conflicts between rule %s and %s", unit.Name, other.QualifiedName(), unit.Rule.QualifiedName())
 			}
-			if !showDisabled && rule.State == types.RuleStateDisabled {
-				log.Printf("[debug] rule %s is disabled, skip", *rule.Arn)
-				continue
+			names[unit.Name] = unit.Rule
+		}
+		for _, unit := range units {
+			paths, err := unit.WriteFiles(opts.Dir)
+			for _, path := range paths {
+				log.Printf("[info] rule %s: wrote %s", unit.Rule.Name, path)
 			}
-			cronExpression, err := app.converter.Convert(*rule.ScheduleExpression)
 			if err != nil {
-				log.Printf("[warn] rule %s: %s", *rule.Name, err.Error())
-				continue
+				return err
 			}
-			fmt.Fprintf(w, "%s\t%s\n", cronExpression, *rule.Name)
 		}
+		return nil
+	})
+}
+
+// RunCronJobWithContext writes the CronJob manifests of Kubernetes for each rule as a multi-document YAML stream.
+func (app *App) RunCronJobWithContext(ctx context.Context, w io.Writer, showDisabled bool, opts CronJobOptions) error {
+	cronJobs := make([]*CronJob, 0)
+	rules := make([]*Rule, 0)
+	return app.convertEachRule(ctx, showDisabled, func(rule *Rule) error {
+		converted, err := app.converter.ConvertToCronJobs(rule, opts)
+		if err != nil {
+			return err
+		}
+		for range converted {
+			rules = append(rules, rule)
+		}
+		cronJobs = append(cronJobs, converted...)
+		return nil
+	}, func() error {
+		names := make(map[string]*Rule, len(cronJobs))
+		for i, cronJob := range cronJobs {
+			if other, ok := names[cronJob.Metadata.Name]; ok {
+				return fmt.Errorf("CronJob %s conflicts between rule %s and %s", cronJob.Metadata.Name, other.QualifiedName(), rules[i].QualifiedName())
+			}
+			names[cronJob.Metadata.Name] = rules[i]
+		}
+		return WriteCronJobs(w, cronJobs)
+	})
+}
+
+// RunICalendarWithContext writes the fire times of the rules in the period as iCalendar.
+func (app *App) RunICalendarWithContext(ctx context.Context, w io.Writer, showDisabled bool, opts ICalendarOptions) error {
+	events := make([]*ICalendarEvent, 0)
+	return app.convertEachRule(ctx, showDisabled, func(rule *Rule) error {
+		converted, err := NewICalendarEvents(rule, opts)
+		if err != nil {
+			return err
+		}
+		events = append(events, converted...)
+		return nil
+	}, func() error {
+		return WriteICalendar(w, events, time.Now())
+	})
+}
+
+// RunTimelineWithContext writes the HTML timeline of the fire times of the rules.
+func (app *App) RunTimelineWithContext(ctx context.Context, w io.Writer, showDisabled bool, opts TimelineOptions) error {
+	if opts.TimeZone == nil {
+		opts.TimeZone = app.converter.TimeZone
+	}
+	timeline := NewTimeline(opts)
+	return app.convertEachRule(ctx, showDisabled, timeline.AddRule, func() error {
+		return timeline.WriteHTML(w)
+	})
+}
+
+// DiffOptions is the options for RunDiffWithContext.
+type DiffOptions struct {
+	// Declared is the source of the declared rules, such as CloudFormationSource or TerraformSource.
+	Declared Source
+	// Format is the output format, one of DriftFormats.
+	Format string
+}
+
+// RunDiffWithContext writes the drifts between the declared rules and the rules of the source of App, the live rules.
+// Both of the rules are selected by the name filters of App, and it returns *DriftError if any drift is found.
+// The tags and the states of the filter are not available, since the declared rules have no tags and the states are compared.
+func (app *App) RunDiffWithContext(ctx context.Context, w io.Writer, opts DiffOptions) error {
+	if err := WriteDrifts(io.Discard, opts.Format, nil); err != nil {
+		return err
+	}
+	if len(app.options.Filter.Tags) > 0 || len(app.options.Filter.States) > 0 {
+		return fmt.Errorf("tag and state filters are not available in diff")
+	}
+	filter := &app.options.Filter
+	drifts, err := Diff(ctx, &filteredSource{src: opts.Declared, filter: filter}, &filteredSource{src: app.source, filter: filter})
+	if err != nil {
+		return err
+	}
+	if err := WriteDrifts(w, opts.Format, drifts); err != nil {
+		return err
+	}
+	log.Printf("[info] %d drifts found", len(drifts))
+	if len(drifts) > 0 {
+		return &DriftError{Drifts: drifts}
 	}
 	return nil
 }
+
+// convertEachRule calls convert for each rule, and write after all rules are converted.
+// The rules which convert fails are skipped with a warning, and reported at the end.
+func (app *App) convertEachRule(ctx context.Context, showDisabled bool, convert func(*Rule) error, write func() error) error {
+	report := &ConversionReport{}
+	err := app.eachRule(ctx, showDisabled, func(rule *Rule) error {
+		if err := convert(rule); err != nil {
+			log.Printf("[warn] rule %s: [%s] %s", rule.Name, CodeOf(err), err.Error())
+			report.addSkipped(rule.Name, err)
+			return nil
+		}
+		report.addConverted()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	report.log()
+	if app.options.FailOnSkip && len(report.Skipped) > 0 {
+		return &SkippedRulesError{Report: report}
+	}
+	return nil
+}
+
+// eachRule calls fn for each rule of the source selected by the filter, skipping the disabled rules unless showDisabled or States of the filter is set.
+func (app *App) eachRule(ctx context.Context, showDisabled bool, fn func(*Rule) error) error {
+	return app.source.Rules(ctx, func(rule *Rule) error {
+		for i := range app.options.Overrides {
+			if !app.options.Overrides[i].apply(rule) {
+				log.Printf("[debug] rule %s is skipped by the override %s", rule.Name, app.options.Overrides[i].Name)
+				return nil
+			}
+		}
+		if !app.options.Filter.Match(rule) {
+			log.Printf("[debug] rule %s is not selected, skip", rule.Name)
+			return nil
+		}
+		if len(app.options.Filter.States) == 0 && !showDisabled && rule.State == "DISABLED" {
+			log.Printf("[debug] rule %s is disabled, skip", rule.Name)
+			return nil
+		}
+		return fn(rule)
+	})
+}
+
+// convertRule converts the rule into the records.
+// The records are annotated with the valid period if the schedules are split into multiple periods.
+func (app *App) convertRule(rule *Rule) ([]*Record, error) {
+	expr, err := ParseExpression(rule.ScheduleExpression)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := app.convert(expr, rule.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	var warning string
+	if err := app.checkLossless(expr, rule.TimeZone); err != nil {
+		warning = err.Error()
+	}
+	split := false
+	for _, s := range schedules {
+		if !s.From.Equal(schedules[0].From) {
+			split = true
+			break
+		}
+	}
+	records := make([]*Record, 0, len(schedules))
+	for _, s := range schedules {
+		r := &Record{
+			Name:                       rule.Name,
+			Arn:                        rule.Arn,
+			EventBusName:               rule.EventBusName,
+			State:                      rule.State,
+			ScheduleExpression:         rule.ScheduleExpression,
+			ScheduleExpressionTimezone: rule.TimeZone.String(),
+			Cron:                       s.String(),
+			Warning:                    warning,
+			Account:                    rule.Account,
+			Region:                     rule.Region,
+		}
+		if app.options.WithTargets {
+			r.Targets = rule.Targets
+		}
+		if split {
+			from, to := s.From.In(app.converter.TimeZone), s.To.In(app.converter.TimeZone)
+			r.ValidFrom, r.ValidTo = &from, &to
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (app *App) convert(expr Expression, base *time.Location) ([]*PeriodSchedule, error) {
+	if app.isPeriod() {
+		return app.converter.ConvertPeriod(expr, base, app.options.From, app.options.To)
+	}
+	schedules, err := app.converter.ConvertToSchedules(expr, base)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*PeriodSchedule, 0, len(schedules))
+	for _, s := range schedules {
+		ret = append(ret, &PeriodSchedule{Schedule: s})
+	}
+	return ret, nil
+}
+
+// checkLossless checks the expression as converted, L, W and # are exact with the period since the month is pinned.
+func (app *App) checkLossless(expr Expression, base *time.Location) error {
+	c := *app.converter
+	c.pinMonth = app.isPeriod()
+	return c.checkLossless(expr, base)
+}
+
+func (app *App) isPeriod() bool {
+	return !app.options.From.IsZero() && !app.options.To.IsZero()
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
@@ -20,23 +26,344 @@ var (
 )
 
 func main() {
-	var (
-		refDate      string
-		tz           string
-		minLevel     string
-		showDisabled bool
-	)
-	flag.CommandLine.Usage = func() {
-		fmt.Fprintln(flag.CommandLine.Output(), "rules2json is cron-like notation converter for ScheduleExpression in EventBridge's Rule")
-		fmt.Fprintln(flag.CommandLine.Output(), "version:", Version)
-		flag.CommandLine.PrintDefaults()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "next":
+			runNext(args[1:])
+			return
+		case "systemd":
+			runSystemd(args[1:])
+			return
+		case "cronjob":
+			runCronJob(args[1:])
+			return
+		case "ics":
+			runICalendar(args[1:])
+			return
+		case "timeline":
+			runTimeline(args[1:])
+			return
+		case "from-crontab":
+			runFromCrontab(args[1:])
+			return
+		case "diff":
+			runDiff(args[1:])
+			return
+		}
+	}
+	runConvert(args)
+}
+
+// commonFlags is the flags shared by the subcommands.
+type commonFlags struct {
+	minLevel         string
+	tz               string
+	showDisabled     bool
+	input            string
+	template         string
+	parameters       keyValueFlags
+	terraform        string
+	eventBuses       string
+	namePrefix       string
+	include          regexpFlags
+	exclude          regexpFlags
+	tags             keyValueFlags
+	states           string
+	regions          string
+	profiles         string
+	roleArns         string
+	concurrency      int
+	withoutScheduler bool
+	withTargets      bool
+	config           string
+	overrides        []rules2cron.RuleOverride
+	output           string
+}
+
+// keyValueFlags is the repeatable Key=Value flag, such as the template parameters and the tags.
+type keyValueFlags map[string]string
+
+func (p keyValueFlags) String() string {
+	return fmt.Sprint(map[string]string(p))
+}
+
+func (p keyValueFlags) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("must be Key=Value: %q", v)
+	}
+	p[kv[0]] = kv[1]
+	return nil
+}
+
+// regexpFlags is the repeatable flag of the regular expressions.
+type regexpFlags []*regexp.Regexp
+
+func (r *regexpFlags) String() string {
+	patterns := make([]string, 0, len(*r))
+	for _, p := range *r {
+		patterns = append(patterns, p.String())
+	}
+	return strings.Join(patterns, ", ")
+}
+
+func (r *regexpFlags) Set(v string) error {
+	p, err := regexp.Compile(v)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, p)
+	return nil
+}
+
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.minLevel, "log-level", "info", "rules2json log level")
+	c.registerConfig(fs)
+	fs.StringVar(&c.tz, "tz", "UTC", "Which time zone to convert to")
+	fs.BoolVar(&c.showDisabled, "show-disabled", false, "show disabled rules")
+	c.registerSource(fs)
+	c.registerAWS(fs)
+	c.registerFilter(fs)
+	c.registerSelection(fs)
+	fs.BoolVar(&c.withTargets, "with-targets", false, "attach the targets of the rules to the structured formats, listed with ListTargetsByRule")
+}
+
+// setOptions sets the options of the flags registered by register.
+func (c *commonFlags) setOptions(o *rules2cron.AppOptions) {
+	o.Source = c.source()
+	c.setAWSOptions(o)
+	o.Filter = c.filter()
+	o.WithTargets = c.withTargets
+	o.Overrides = c.overrides
+}
+
+// registerConfig registers the flag of the config file.
+func (c *commonFlags) registerConfig(fs *flag.FlagSet) {
+	fs.StringVar(&c.config, "config", "", "read the options from the YAML or JSON `file`, overridden by the flags (default "+strings.Join(rules2cron.ConfigFileNames, ", ")+" or rules2cron/config.yaml in the user config directory if exists)")
+}
+
+// loadConfig sets the values of the config file to the flags which are not set in the command line.
+// The keys whose flags are not available in the subcommand are ignored with a warning.
+func (c *commonFlags) loadConfig(fs *flag.FlagSet) {
+	path := c.config
+	if path == "" {
+		if path = rules2cron.FindConfig(); path == "" {
+			return
+		}
+	}
+	cfg, err := rules2cron.LoadConfig(path)
+	if err != nil {
+		log.Fatalln("[error] ", err)
 	}
-	flag.StringVar(&minLevel, "log-level", "info", "rules2json log level")
-	flag.StringVar(&refDate, "ref-date", time.Now().Format("2006-01-01"), "date of conversion basis")
-	flag.StringVar(&tz, "tz", "UTC", "Which time zone to convert to")
-	flag.BoolVar(&showDisabled, "show-disabled", false, "show disabled rules")
-	flag.Parse()
+	log.Printf("[debug] load config %s", path)
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	ignored := make(map[string]bool)
+	for _, v := range configFlags(cfg) {
+		if fs.Lookup(v.flag) == nil {
+			if !ignored[v.key] {
+				log.Printf("[warn] config %s: %s is not available in %s, ignored", path, v.key, fs.Name())
+				ignored[v.key] = true
+			}
+			continue
+		}
+		if set[v.flag] {
+			continue
+		}
+		if err := fs.Set(v.flag, v.value); err != nil {
+			log.Fatalf("[error] config %s: %s: %s", path, v.key, err)
+		}
+	}
+	c.overrides = cfg.Overrides
+}
+
+// configFlag is the value of the config key set to the flag.
+type configFlag struct {
+	key   string
+	flag  string
+	value string
+}
+
+// configFlags returns the values of the config with the flags, in the order to set.
+func configFlags(cfg *rules2cron.Config) []configFlag {
+	flags := make([]configFlag, 0)
+	add := func(key, name, value string) {
+		if value != "" {
+			flags = append(flags, configFlag{key: key, flag: name, value: value})
+		}
+	}
+	addMap := func(key, name string, m map[string]string) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			add(key, name, k+"="+m[k])
+		}
+	}
+	add("tz", "tz", cfg.TimeZone)
+	add("ref_date", "ref-date", cfg.RefDate)
+	if cfg.ShowDisabled {
+		add("show_disabled", "show-disabled", "true")
+	}
+	if cfg.WithTargets {
+		add("with_targets", "with-targets", "true")
+	}
+	add("source.input", "input", cfg.Source.Input)
+	add("source.template", "template", cfg.Source.Template)
+	addMap("source.parameters", "parameter", cfg.Source.Parameters)
+	add("source.terraform", "terraform", cfg.Source.Terraform)
+	add("aws.event_buses", "event-bus", strings.Join(cfg.AWS.EventBuses, ","))
+	add("aws.regions", "regions", strings.Join(cfg.AWS.Regions, ","))
+	add("aws.profiles", "profiles", strings.Join(cfg.AWS.Profiles, ","))
+	add("aws.role_arns", "role-arns", strings.Join(cfg.AWS.RoleArns, ","))
+	if cfg.AWS.Concurrency > 0 {
+		add("aws.concurrency", "concurrency", strconv.Itoa(cfg.AWS.Concurrency))
+	}
+	if cfg.AWS.WithoutScheduler {
+		add("aws.without_scheduler", "without-scheduler", "true")
+	}
+	add("filter.name_prefix", "name-prefix", cfg.Filter.NamePrefix)
+	for _, p := range cfg.Filter.Include {
+		add("filter.include", "include", p)
+	}
+	for _, p := range cfg.Filter.Exclude {
+		add("filter.exclude", "exclude", p)
+	}
+	addMap("filter.tags", "tag", cfg.Filter.Tags)
+	add("filter.states", "state", strings.Join(cfg.Filter.States, ","))
+	add("output.format", "format", cfg.Output.Format)
+	add("output.file", "output", cfg.Output.File)
+	return flags
+}
 
+// formatFlag is the flag of the output format, one of formats.
+type formatFlag struct {
+	value   string
+	formats []string
+}
+
+func (f *formatFlag) String() string {
+	return f.value
+}
+
+func (f *formatFlag) Set(v string) error {
+	for _, format := range f.formats {
+		if strings.EqualFold(v, format) {
+			f.value = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, available formats are %s", v, strings.Join(f.formats, ", "))
+}
+
+// registerFormat registers the flag of the output format, the first of formats is the default.
+func registerFormat(fs *flag.FlagSet, formats []string) *formatFlag {
+	f := &formatFlag{value: formats[0], formats: formats}
+	fs.Var(f, "format", "output format: "+strings.Join(formats, ", "))
+	return f
+}
+
+// registerOutput registers the flag of the output file.
+func (c *commonFlags) registerOutput(fs *flag.FlagSet) {
+	fs.StringVar(&c.output, "output", "", "write to the `file` instead of stdout")
+}
+
+// openOutput creates the file of -output, stdout if empty.
+func (c *commonFlags) openOutput() *os.File {
+	if c.output == "" {
+		return os.Stdout
+	}
+	f, err := os.Create(c.output)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	return f
+}
+
+// registerFilter registers the flags which select the rules by the name.
+func (c *commonFlags) registerFilter(fs *flag.FlagSet) {
+	fs.StringVar(&c.namePrefix, "name-prefix", "", "select the rules whose name starts with the prefix")
+	fs.Var(&c.include, "include", "select the rules whose name matches the regular expression (repeatable)")
+	fs.Var(&c.exclude, "exclude", "drop the rules whose name matches the regular expression (repeatable)")
+}
+
+// registerSelection registers the flags which select the rules by the tags and the state.
+func (c *commonFlags) registerSelection(fs *flag.FlagSet) {
+	c.tags = make(keyValueFlags)
+	fs.Var(c.tags, "tag", "select the rules which have the tag Key=Value, only with the rules of AWS (repeatable)")
+	fs.StringVar(&c.states, "state", "", "comma separated states of the rules to select, "+strings.Join(rules2cron.RuleStates, ", ")+" (default all but DISABLED, all with -show-disabled)")
+}
+
+// filter returns the filter of the flags registered by registerFilter and registerSelection.
+func (c *commonFlags) filter() rules2cron.RuleFilter {
+	states := splitList(c.states)
+	for _, state := range states {
+		valid := false
+		for _, s := range rules2cron.RuleStates {
+			valid = valid || strings.EqualFold(state, s)
+		}
+		if !valid {
+			log.Fatalf("[error] unknown state %q, available states are %s", state, strings.Join(rules2cron.RuleStates, ", "))
+		}
+	}
+	return rules2cron.RuleFilter{
+		NamePrefix: c.namePrefix,
+		Include:    c.include,
+		Exclude:    c.exclude,
+		Tags:       c.tags,
+		States:     states,
+	}
+}
+
+// registerAWS registers the flags which select the event buses, the regions and the accounts to list the rules from AWS.
+func (c *commonFlags) registerAWS(fs *flag.FlagSet) {
+	fs.StringVar(&c.eventBuses, "event-bus", "", "comma separated event bus names to list the rules from AWS (default all the event buses)")
+	fs.StringVar(&c.regions, "regions", "", "comma separated regions to scan")
+	fs.StringVar(&c.profiles, "profiles", "", "comma separated profiles of the shared config to scan")
+	fs.StringVar(&c.roleArns, "role-arns", "", "comma separated role ARNs to scan, assumed on the default credentials")
+	fs.IntVar(&c.concurrency, "concurrency", 4, "number of the regions and the accounts scanned at the same time")
+	fs.BoolVar(&c.withoutScheduler, "without-scheduler", false, "skip the schedules of EventBridge Scheduler, not calling ListSchedules and GetSchedule")
+}
+
+// setAWSOptions sets the options of the flags registered by registerAWS.
+func (c *commonFlags) setAWSOptions(o *rules2cron.AppOptions) {
+	o.EventBusNames = splitList(c.eventBuses)
+	o.Regions = splitList(c.regions)
+	o.Profiles = splitList(c.profiles)
+	o.RoleArns = splitList(c.roleArns)
+	o.Concurrency = c.concurrency
+	o.WithoutScheduler = c.withoutScheduler
+}
+
+// splitList splits the comma separated list, nil if empty.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// registerSource registers the flags which select the source of the rules.
+func (c *commonFlags) registerSource(fs *flag.FlagSet) {
+	fs.StringVar(&c.input, "input", "", "read the rules from the JSON `file` of aws events list-rules instead of AWS, - for stdin")
+	fs.StringVar(&c.template, "template", "", "read the schedules from the CloudFormation or SAM template instead of AWS, - for stdin")
+	c.parameters = make(keyValueFlags)
+	fs.Var(c.parameters, "parameter", "template parameter as Key=Value, overrides the default value (repeatable)")
+	fs.StringVar(&c.terraform, "terraform", "", "read the schedules from the JSON `file` of terraform show -json, a plan or the state, instead of AWS, - for stdin")
+}
+
+func (c *commonFlags) setupLogger() {
 	filter := &logutils.LevelFilter{
 		Levels: []logutils.LogLevel{"debug", "info", "notice", "warn", "error"},
 		ModifierFuncs: []logutils.ModifierFunc{
@@ -46,29 +373,496 @@ func main() {
 			logutils.Color(color.FgYellow),
 			logutils.Color(color.FgRed, color.BgBlack),
 		},
-		MinLevel: logutils.LogLevel(strings.ToLower(minLevel)),
+		MinLevel: logutils.LogLevel(strings.ToLower(c.minLevel)),
 		Writer:   os.Stderr,
 	}
 	log.SetOutput(filter)
-	date, err := time.Parse("2006-01-02", refDate)
+}
+
+// source returns the source of the rules, nil for AWS.
+func (c *commonFlags) source() rules2cron.Source {
+	n := 0
+	for _, name := range []string{c.input, c.template, c.terraform} {
+		if name != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		log.Fatalln("[error] only one of -input, -template and -terraform can be used")
+	}
+	switch {
+	case c.input != "":
+		return rules2cron.NewJSONSource(openInput(c.input))
+	case c.template != "":
+		return rules2cron.NewCloudFormationSource(openInput(c.template), c.parameters)
+	case c.terraform != "":
+		return rules2cron.NewTerraformSource(openInput(c.terraform))
+	}
+	return nil
+}
+
+// openInput opens the file, - for stdin.
+func openInput(name string) io.Reader {
+	if name == "-" {
+		return os.Stdin
+	}
+	f, err := os.Open(name)
 	if err != nil {
 		log.Fatalln("[error] ", err)
 	}
-	loc, err := time.LoadLocation(tz)
+	return f
+}
+
+func (c *commonFlags) location() *time.Location {
+	loc, err := time.LoadLocation(c.tz)
 	if err != nil {
 		log.Println("[wan] can not load location, use UTC: ", err)
 		loc = time.UTC
 	}
+	return loc
+}
+
+// parseDate parses the date of the flag as the midnight in the location.
+func parseDate(value string, loc *time.Location) time.Time {
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	return t
+}
+
+func runConvert(args []string) {
+	var (
+		common     commonFlags
+		refDate    string
+		from       string
+		to         string
+		strict     bool
+		failOnSkip bool
+	)
+	fs := flag.NewFlagSet("rules2cron", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2json is cron-like notation converter for ScheduleExpression in EventBridge's Rule")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fmt.Fprintln(fs.Output(), "subcommands:")
+		fmt.Fprintln(fs.Output(), "  next\tlist the next fire times of each rule")
+		fmt.Fprintln(fs.Output(), "  systemd\twrite the timer and service units of systemd for each rule")
+		fmt.Fprintln(fs.Output(), "  cronjob\twrite the CronJob manifests of Kubernetes for each rule")
+		fmt.Fprintln(fs.Output(), "  ics\twrite the fire times of the rules as iCalendar")
+		fmt.Fprintln(fs.Output(), "  timeline\twrite the HTML timeline of the fire times of the rules")
+		fmt.Fprintln(fs.Output(), "  from-crontab\tconvert crontab into EventBridge's cron() expressions")
+		fmt.Fprintln(fs.Output(), "  diff\treport the drifts between the declared rules and the live rules")
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	common.registerOutput(fs)
+	fs.StringVar(&refDate, "ref-date", time.Now().Format("2006-01-02"), "date of conversion basis")
+	fs.StringVar(&from, "from", "", "start date of the conversion period, split the schedules at the daylight saving time transitions and by month for L, W and #")
+	fs.StringVar(&to, "to", "", "end date of the conversion period (exclusive)")
+	fs.BoolVar(&strict, "strict", false, "skip the rules which crontab can not represent exactly")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any rules can not be converted")
+	format := registerFormat(fs, rules2cron.Formats)
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	formatter, err := rules2cron.NewFormatter(format.value)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+
+	date, err := time.Parse("2006-01-02", refDate)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	loc := common.location()
+	var fromDate, toDate time.Time
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			log.Fatalln("[error] both -from and -to are required")
+		}
+		fromDate, toDate = parseDate(from, loc), parseDate(to, loc)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{
+		ReferenceDate: date,
+		TimeZone:      loc,
+		Strict:        strict,
+	}, func(o *rules2cron.AppOptions) {
+		o.From = fromDate
+		o.To = toDate
+		o.FailOnSkip = failOnSkip
+		common.setOptions(o)
+		o.Formatter = formatter
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunWithContext(ctx, w, common.showDisabled); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runNext(args []string) {
+	var (
+		common commonFlags
+		start  string
+		n      int
+	)
+	fs := flag.NewFlagSet("rules2cron next", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron next lists the next fire times of each rule")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	common.registerOutput(fs)
+	fs.StringVar(&start, "start", "", "start time in RFC3339 (default now)")
+	fs.IntVar(&n, "n", 5, "number of fire times for each rule")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	loc := common.location()
+	startTime := time.Now()
+	if start != "" {
+		var err error
+		if startTime, err = time.Parse(time.RFC3339, start); err != nil {
+			log.Fatalln("[error] ", err)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{
+		ReferenceDate: startTime,
+		TimeZone:      loc,
+	}, func(o *rules2cron.AppOptions) {
+		common.setOptions(o)
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunNextWithContext(ctx, w, common.showDisabled, startTime, n); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runSystemd(args []string) {
+	var (
+		common     commonFlags
+		refDate    string
+		strict     bool
+		failOnSkip bool
+		opts       rules2cron.SystemdOptions
+	)
+	fs := flag.NewFlagSet("rules2cron systemd", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron systemd writes the timer and service units of systemd for each rule")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	fs.StringVar(&refDate, "ref-date", time.Now().Format("2006-01-02"), "date of conversion basis for L, W and #")
+	fs.BoolVar(&strict, "strict", false, "skip the rules which systemd can not represent exactly")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any rules can not be converted")
+	fs.StringVar(&opts.Dir, "output-dir", ".", "directory to write the units")
+	fs.StringVar(&opts.UnitPrefix, "unit-prefix", "rules2cron-", "prefix of the unit names")
+	fs.StringVar(&opts.ExecStart, "exec-start", "/bin/true", "ExecStart= of the service units")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	date, err := time.Parse("2006-01-02", refDate)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{
+		ReferenceDate: date,
+		TimeZone:      common.location(),
+		Strict:        strict,
+	}, func(o *rules2cron.AppOptions) {
+		o.FailOnSkip = failOnSkip
+		common.setOptions(o)
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	if err := app.RunSystemdWithContext(ctx, common.showDisabled, opts); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runCronJob(args []string) {
+	var (
+		common      commonFlags
+		refDate     string
+		strict      bool
+		failOnSkip  bool
+		jobTemplate string
+		opts        rules2cron.CronJobOptions
+	)
+	fs := flag.NewFlagSet("rules2cron cronjob", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron cronjob writes the CronJob manifests of Kubernetes for each rule")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	common.registerOutput(fs)
+	fs.StringVar(&refDate, "ref-date", time.Now().Format("2006-01-02"), "date of conversion basis")
+	fs.BoolVar(&strict, "strict", false, "skip the rules which crontab can not represent exactly")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any rules can not be converted")
+	fs.StringVar(&opts.Namespace, "namespace", "", "namespace of the CronJobs")
+	fs.StringVar(&jobTemplate, "job-template", "", "file of the Go template which renders the jobTemplate in YAML")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	date, err := time.Parse("2006-01-02", refDate)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	if jobTemplate != "" {
+		if opts.JobTemplate, err = template.ParseFiles(jobTemplate); err != nil {
+			log.Fatalln("[error] ", err)
+		}
+	}
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 	app, err := rules2cron.New(ctx, &rules2cron.Converter{
 		ReferenceDate: date,
+		TimeZone:      common.location(),
+		Strict:        strict,
+	}, func(o *rules2cron.AppOptions) {
+		o.FailOnSkip = failOnSkip
+		common.setOptions(o)
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunCronJobWithContext(ctx, w, common.showDisabled, opts); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runICalendar(args []string) {
+	var (
+		common     commonFlags
+		from       string
+		to         string
+		failOnSkip bool
+		opts       rules2cron.ICalendarOptions
+	)
+	fs := flag.NewFlagSet("rules2cron ics", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron ics writes the fire times of the rules as iCalendar")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	common.registerOutput(fs)
+	today := time.Now().Format("2006-01-02")
+	fs.StringVar(&from, "from", today, "start date of the period")
+	fs.StringVar(&to, "to", "", "end date of the period (exclusive, default 30 days after -from)")
+	fs.DurationVar(&opts.Duration, "duration", 15*time.Minute, "duration of the events")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any rules can not be converted")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	loc := common.location()
+	opts.From = parseDate(from, loc)
+	opts.To = opts.From.AddDate(0, 0, 30)
+	if to != "" {
+		opts.To = parseDate(to, loc)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{
+		ReferenceDate: opts.From,
+		TimeZone:      loc,
+	}, func(o *rules2cron.AppOptions) {
+		o.FailOnSkip = failOnSkip
+		common.setOptions(o)
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunICalendarWithContext(ctx, w, common.showDisabled, opts); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runTimeline(args []string) {
+	var (
+		common     commonFlags
+		start      string
+		failOnSkip bool
+		opts       rules2cron.TimelineOptions
+	)
+	fs := flag.NewFlagSet("rules2cron timeline", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron timeline writes the HTML timeline of the fire times of the rules")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	common.register(fs)
+	common.registerOutput(fs)
+	fs.StringVar(&start, "start", "", "start time in RFC3339 (default the beginning of today)")
+	fs.DurationVar(&opts.Duration, "duration", 24*time.Hour, "duration of the timeline")
+	fs.StringVar(&opts.Title, "title", "rules2cron", "title of the report")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any rules can not be converted")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	loc := common.location()
+	now := time.Now().In(loc)
+	opts.Start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if start != "" {
+		var err error
+		if opts.Start, err = time.Parse(time.RFC3339, start); err != nil {
+			log.Fatalln("[error] ", err)
+		}
+	}
+	opts.TimeZone = loc
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{
+		ReferenceDate: opts.Start,
 		TimeZone:      loc,
+	}, func(o *rules2cron.AppOptions) {
+		o.FailOnSkip = failOnSkip
+		common.setOptions(o)
+	})
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunTimelineWithContext(ctx, w, common.showDisabled, opts); err != nil {
+		log.Fatalln("[error] ", err)
+	}
+}
+
+func runFromCrontab(args []string) {
+	var (
+		common     commonFlags
+		refDate    string
+		strict     bool
+		failOnSkip bool
+	)
+	fs := flag.NewFlagSet("rules2cron from-crontab", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron from-crontab [flags] [crontab file]")
+		fmt.Fprintln(fs.Output(), "converts crontab in -tz into EventBridge's cron() expressions in UTC, reads stdin if the file is omitted")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.minLevel, "log-level", "info", "rules2json log level")
+	common.registerConfig(fs)
+	fs.StringVar(&common.tz, "tz", "UTC", "Which time zone the crontab is in")
+	fs.StringVar(&refDate, "ref-date", time.Now().Format("2006-01-02"), "date of conversion basis")
+	fs.BoolVar(&strict, "strict", false, "skip the lines which shift by the daylight saving time")
+	fs.BoolVar(&failOnSkip, "fail-on-skip", false, "exit with non-zero status when any lines can not be converted")
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+
+	date, err := time.Parse("2006-01-02", refDate)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	in := os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			log.Fatalln("[error] ", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	converter := &rules2cron.Converter{
+		ReferenceDate: date,
+		TimeZone:      common.location(),
+		Strict:        strict,
+	}
+	report, err := converter.ConvertCrontab(os.Stdout, in)
+	if err != nil {
+		log.Fatalln("[error] ", err)
+	}
+	if failOnSkip && len(report.Skipped) > 0 {
+		log.Fatalln("[error] ", &rules2cron.SkippedRulesError{Report: report})
+	}
+}
+
+func runDiff(args []string) {
+	var (
+		common commonFlags
+		live   string
+		opts   rules2cron.DiffOptions
+	)
+	fs := flag.NewFlagSet("rules2cron diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "rules2cron diff reports the drifts between the rules declared in -input, -template or -terraform and the live rules")
+		fmt.Fprintln(fs.Output(), "exits with status 2 when any drift is found")
+		fmt.Fprintln(fs.Output(), "version:", Version)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.minLevel, "log-level", "info", "rules2json log level")
+	common.registerConfig(fs)
+	common.registerSource(fs)
+	common.registerAWS(fs)
+	common.registerFilter(fs)
+	common.registerOutput(fs)
+	fs.StringVar(&live, "live", "", "read the live rules from the JSON `file` of aws events list-rules instead of AWS, - for stdin")
+	format := registerFormat(fs, rules2cron.DriftFormats)
+	fs.Parse(args)
+	common.setupLogger()
+	common.loadConfig(fs)
+	opts.Format = format.value
+	if len(common.overrides) > 0 {
+		log.Println("[warn] the overrides of the config are not applied in diff, the declared rules are compared with the live rules as they are")
+	}
+
+	opts.Declared = common.source()
+	if opts.Declared == nil {
+		log.Fatalln("[error] one of -input, -template and -terraform is required")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app, err := rules2cron.New(ctx, &rules2cron.Converter{}, func(o *rules2cron.AppOptions) {
+		common.setAWSOptions(o)
+		o.Filter = common.filter()
+		if live != "" {
+			o.Source = rules2cron.NewJSONSource(openInput(live))
+		}
 	})
 	if err != nil {
 		log.Fatalln("[error] ", err)
 	}
-	if err := app.RunWithContext(ctx, os.Stdout, showDisabled); err != nil {
+	w := common.openOutput()
+	defer w.Close()
+	if err := app.RunDiffWithContext(ctx, w, opts); err != nil {
+		var driftErr *rules2cron.DriftError
+		if errors.As(err, &driftErr) {
+			log.Println("[warn]", err)
+			os.Exit(2)
+		}
 		log.Fatalln("[error] ", err)
 	}
 }
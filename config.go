@@ -0,0 +1,252 @@
+package rules2cron
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the config file of the repeatable runs, in YAML or JSON.
+// The flags of the command line override the values of the file.
+type Config struct {
+	// TimeZone is the time zone to convert to, such as Asia/Tokyo.
+	TimeZone string `yaml:"tz"`
+	// RefDate is the date of conversion basis in YYYY-MM-DD.
+	RefDate      string `yaml:"ref_date"`
+	ShowDisabled bool   `yaml:"show_disabled"`
+	WithTargets  bool   `yaml:"with_targets"`
+
+	Source ConfigSource `yaml:"source"`
+	AWS    ConfigAWS    `yaml:"aws"`
+	Filter ConfigFilter `yaml:"filter"`
+	Output ConfigOutput `yaml:"output"`
+
+	// Overrides is applied to the rules in order, see RuleOverride.
+	Overrides []RuleOverride `yaml:"overrides"`
+}
+
+// ConfigSource selects the source of the rules, AWS if all of them are empty.
+// The paths are relative to the directory of the config file, - for stdin.
+type ConfigSource struct {
+	// Input is the JSON of aws events list-rules.
+	Input string `yaml:"input"`
+	// Template is the CloudFormation or SAM template, and Parameters is its parameters.
+	Template   string            `yaml:"template"`
+	Parameters map[string]string `yaml:"parameters"`
+	// Terraform is the JSON of terraform show -json.
+	Terraform string `yaml:"terraform"`
+}
+
+// ConfigAWS is the event buses, the regions and the accounts to list the rules from AWS, see AppOptions.
+type ConfigAWS struct {
+	EventBuses  []string `yaml:"event_buses"`
+	Regions     []string `yaml:"regions"`
+	Profiles    []string `yaml:"profiles"`
+	RoleArns    []string `yaml:"role_arns"`
+	Concurrency int      `yaml:"concurrency"`
+
+	WithoutScheduler bool `yaml:"without_scheduler"`
+}
+
+// ConfigFilter is the filter of the rules, see RuleFilter.
+// Include and Exclude are the regular expressions.
+type ConfigFilter struct {
+	NamePrefix string            `yaml:"name_prefix"`
+	Include    []string          `yaml:"include"`
+	Exclude    []string          `yaml:"exclude"`
+	Tags       map[string]string `yaml:"tags"`
+	States     []string          `yaml:"states"`
+}
+
+// ConfigOutput is the output of the run.
+type ConfigOutput struct {
+	// Format is the name of the format, one of Formats.
+	Format string `yaml:"format"`
+	// File is the file to write, relative to the directory of the config file. Stdout if empty.
+	File string `yaml:"file"`
+}
+
+// RuleOverride overrides the rules whose name matches Name, such as the rules whose time zone is unknown in the offline sources.
+type RuleOverride struct {
+	// Name is the regular expression matched with the name of the rule, such as `^batch/`.
+	Name string `yaml:"name"`
+	// Skip drops the rules.
+	Skip bool `yaml:"skip"`
+	// State, ScheduleExpression, TimeZone and Description replace the values of the rules if set.
+	State              string `yaml:"state"`
+	ScheduleExpression string `yaml:"schedule_expression"`
+	TimeZone           string `yaml:"tz"`
+	Description        string `yaml:"description"`
+
+	pattern  *regexp.Regexp
+	location *time.Location
+}
+
+// compile parses the name, the expression and the time zone of the override.
+func (o *RuleOverride) compile() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	var err error
+	if o.pattern, err = regexp.Compile(o.Name); err != nil {
+		return fmt.Errorf("name: %w", err)
+	}
+	if o.State != "" {
+		if err := validateState(o.State); err != nil {
+			return fmt.Errorf("state: %w", err)
+		}
+	}
+	if o.ScheduleExpression != "" {
+		if _, err := ParseExpression(o.ScheduleExpression); err != nil {
+			return fmt.Errorf("schedule_expression: %w", err)
+		}
+	}
+	if o.TimeZone != "" {
+		if o.location, err = time.LoadLocation(o.TimeZone); err != nil {
+			return fmt.Errorf("tz: %w", err)
+		}
+	}
+	return nil
+}
+
+// apply overrides the rule if the name matches, and reports whether the rule is kept.
+func (o *RuleOverride) apply(rule *Rule) bool {
+	if !o.pattern.MatchString(rule.Name) {
+		return true
+	}
+	if o.Skip {
+		return false
+	}
+	if o.State != "" {
+		rule.State = strings.ToUpper(o.State)
+	}
+	if o.ScheduleExpression != "" {
+		rule.ScheduleExpression = o.ScheduleExpression
+	}
+	if o.location != nil {
+		rule.TimeZone = o.location
+	}
+	if o.Description != "" {
+		rule.Description = o.Description
+	}
+	return true
+}
+
+// ConfigFileNames is the names of the config file searched by FindConfig.
+var ConfigFileNames = []string{"rules2cron.yaml", "rules2cron.yml", "rules2cron.json"}
+
+// FindConfig returns the path of the config file in the current directory, named one of ConfigFileNames,
+// or config.yaml, config.yml or config.json in rules2cron of the user config directory, such as ~/.config/rules2cron/config.yaml.
+// Empty if not found.
+func FindConfig() string {
+	candidates := append([]string{}, ConfigFileNames...)
+	if dir, err := os.UserConfigDir(); err == nil {
+		for _, name := range []string{"config.yaml", "config.yml", "config.json"} {
+			candidates = append(candidates, filepath.Join(dir, "rules2cron", name))
+		}
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads and validates the config file, the unknown keys are errors.
+// The paths in the file are resolved relative to the directory of the file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for _, p := range []*string{&cfg.Source.Input, &cfg.Source.Template, &cfg.Source.Terraform, &cfg.Output.File} {
+		if *p != "" && *p != "-" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	return cfg, nil
+}
+
+// Validate reports the first invalid value of the config, with the key of the value.
+func (cfg *Config) Validate() error {
+	if cfg.TimeZone != "" {
+		if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+			return fmt.Errorf("tz: %w", err)
+		}
+	}
+	if cfg.RefDate != "" {
+		if _, err := time.Parse("2006-01-02", cfg.RefDate); err != nil {
+			return fmt.Errorf("ref_date: must be YYYY-MM-DD: %q", cfg.RefDate)
+		}
+	}
+	n := 0
+	for _, name := range []string{cfg.Source.Input, cfg.Source.Template, cfg.Source.Terraform} {
+		if name != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return fmt.Errorf("source: only one of input, template and terraform can be set")
+	}
+	if len(cfg.Source.Parameters) > 0 && cfg.Source.Template == "" {
+		return fmt.Errorf("source.parameters: available only with source.template")
+	}
+	if cfg.AWS.Concurrency < 0 {
+		return fmt.Errorf("aws.concurrency: must not be negative: %d", cfg.AWS.Concurrency)
+	}
+	for i, p := range cfg.Filter.Include {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("filter.include[%d]: %w", i, err)
+		}
+	}
+	for i, p := range cfg.Filter.Exclude {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("filter.exclude[%d]: %w", i, err)
+		}
+	}
+	for i, state := range cfg.Filter.States {
+		if err := validateState(state); err != nil {
+			return fmt.Errorf("filter.states[%d]: %w", i, err)
+		}
+	}
+	if cfg.Output.Format != "" {
+		if _, err := NewFormatter(cfg.Output.Format); err != nil {
+			return fmt.Errorf("output.format: %w", err)
+		}
+	}
+	for i := range cfg.Overrides {
+		if err := cfg.Overrides[i].compile(); err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateState reports an error if the state is not one of RuleStates.
+func validateState(state string) error {
+	for _, s := range RuleStates {
+		if strings.EqualFold(state, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown state %q, available states are %s", state, strings.Join(RuleStates, ", "))
+}
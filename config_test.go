@@ -0,0 +1,186 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name      string
+		file      string
+		content   string
+		expected  *rules2cron.Config
+		overrides []string
+		errMsg    string
+	}{
+		{
+			name: "yaml",
+			file: "rules2cron.yaml",
+			content: `
+tz: Asia/Tokyo
+ref_date: "2022-06-01"
+source:
+  template: template.yaml
+  parameters:
+    Hour: "18"
+aws:
+  regions: [ap-northeast-1, us-west-2]
+  concurrency: 2
+  without_scheduler: true
+filter:
+  include: ["daily$"]
+  states: [ENABLED]
+output:
+  format: jsonl
+  file: /tmp/rules.jsonl
+overrides:
+  - name: ^legacy-
+    skip: true
+`,
+			expected: &rules2cron.Config{
+				TimeZone: "Asia/Tokyo",
+				RefDate:  "2022-06-01",
+				Source: rules2cron.ConfigSource{
+					Template:   filepath.Join(dir, "template.yaml"),
+					Parameters: map[string]string{"Hour": "18"},
+				},
+				AWS:    rules2cron.ConfigAWS{Regions: []string{"ap-northeast-1", "us-west-2"}, Concurrency: 2, WithoutScheduler: true},
+				Filter: rules2cron.ConfigFilter{Include: []string{"daily$"}, States: []string{"ENABLED"}},
+				Output: rules2cron.ConfigOutput{Format: "jsonl", File: "/tmp/rules.jsonl"},
+			},
+			overrides: []string{"^legacy-"},
+		},
+		{
+			name:    "json",
+			file:    "rules2cron.json",
+			content: `{"show_disabled": true, "source": {"input": "-"}, "filter": {"tags": {"team": "batch"}}}`,
+			expected: &rules2cron.Config{
+				ShowDisabled: true,
+				Source:       rules2cron.ConfigSource{Input: "-"},
+				Filter:       rules2cron.ConfigFilter{Tags: map[string]string{"team": "batch"}},
+			},
+		},
+		{
+			name:     "empty",
+			file:     "empty.yaml",
+			content:  "",
+			expected: &rules2cron.Config{},
+		},
+		{
+			name:    "unknown key",
+			file:    "unknown.yaml",
+			content: "timezone: Asia/Tokyo\n",
+			errMsg:  "field timezone not found",
+		},
+		{
+			name:    "time zone",
+			file:    "tz.yaml",
+			content: "tz: Mars/Olympus\n",
+			errMsg:  "tz: unknown time zone Mars/Olympus",
+		},
+		{
+			name:    "sources",
+			file:    "sources.yaml",
+			content: "source: {input: rules.json, terraform: plan.json}\n",
+			errMsg:  "source: only one of input, template and terraform can be set",
+		},
+		{
+			name:    "regexp",
+			file:    "regexp.yaml",
+			content: "filter: {exclude: [test, \"(\"]}\n",
+			errMsg:  "filter.exclude[1]: error parsing regexp",
+		},
+		{
+			name:    "state",
+			file:    "state.yaml",
+			content: "filter: {states: [PAUSED]}\n",
+			errMsg:  `filter.states[0]: unknown state "PAUSED"`,
+		},
+		{
+			name:    "format",
+			file:    "format.yaml",
+			content: "output: {format: xml}\n",
+			errMsg:  `output.format: unknown format "xml"`,
+		},
+		{
+			name:    "override",
+			file:    "override.yaml",
+			content: "overrides: [{name: daily, schedule_expression: \"cron(0 18 * *)\"}]\n",
+			errMsg:  "overrides[0]: schedule_expression:",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(dir, c.file)
+			require.NoError(t, os.WriteFile(path, []byte(c.content), 0644))
+			cfg, err := rules2cron.LoadConfig(path)
+			if c.errMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "config "+path+": ")
+				require.Contains(t, err.Error(), c.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0)
+			for _, o := range cfg.Overrides {
+				names = append(names, o.Name)
+			}
+			require.ElementsMatch(t, c.overrides, names)
+			cfg.Overrides = nil
+			require.Equal(t, c.expected, cfg)
+		})
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("HOME", dir)
+
+	require.Equal(t, "", rules2cron.FindConfig())
+	userConfig := filepath.Join(dir, "config", "rules2cron", "config.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(userConfig), 0755))
+	require.NoError(t, os.WriteFile(userConfig, []byte("tz: UTC\n"), 0644))
+	require.Equal(t, userConfig, rules2cron.FindConfig())
+	require.NoError(t, os.WriteFile("rules2cron.json", []byte("{}"), 0644))
+	require.Equal(t, "rules2cron.json", rules2cron.FindConfig())
+}
+
+func TestAppWithOverrides(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+		o.Overrides = []rules2cron.RuleOverride{
+			{Name: "^daily$", ScheduleExpression: "cron(0 12 * * ? *)"},
+			{Name: "^disabled$", State: "enabled"},
+			{Name: "described", Skip: true},
+			{Name: "^batch/", TimeZone: "UTC"},
+		}
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Equal(t, "0 12 * * *\tdaily\n*/5 * * * *\tdisabled\n0 9 * * *\tbatch/schedule\n", buf.String())
+
+	_, err = rules2cron.New(context.Background(), &rules2cron.Converter{}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+		o.Overrides = []rules2cron.RuleOverride{{Name: "daily", TimeZone: "JST"}}
+	})
+	require.EqualError(t, err, "overrides[0]: tz: unknown time zone JST")
+}
@@ -1,9 +1,7 @@
 package rules2cron
 
 import (
-	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -11,365 +9,382 @@ import (
 type Converter struct {
 	ReferenceDate time.Time
 	TimeZone      *time.Location
+	// Strict makes the lossy conversions fail with *LossyConversionError.
+	Strict bool
+
+	// pinMonth pins the month field to the month of ReferenceDate.
+	pinMonth bool
+	// lastDayItem shifts the day before the 1st into L instead of the last day of the reference month, for EventBridge.
+	lastDayItem bool
 }
 
 func (c *Converter) Convert(scheduleExpression string) (string, error) {
-	if c.TimeZone == nil {
-		c.TimeZone = time.Local
+	return c.ConvertInLocation(scheduleExpression, c.ReferenceDate.Location())
+}
+
+// ConvertInLocation converts a schedule expression evaluated in the given location,
+// such as the ScheduleExpressionTimezone of EventBridge Scheduler's schedule.
+func (c *Converter) ConvertInLocation(scheduleExpression string, base *time.Location) (string, error) {
+	expr, err := ParseExpression(scheduleExpression)
+	if err != nil {
+		return "", err
 	}
-	switch {
-	case strings.HasPrefix(scheduleExpression, "rate("):
-		return c.convertRate(scheduleExpression)
-	case strings.HasPrefix(scheduleExpression, "cron("):
-		return c.convertCron(scheduleExpression)
-	default:
-		return "", errors.New("invalid format")
+	return c.ConvertExpression(expr, base)
+}
+
+// ConvertExpression converts a parsed schedule expression evaluated in the given location.
+// When the expression needs multiple crontab lines, the lines are joined with a newline.
+func (c *Converter) ConvertExpression(expr Expression, base *time.Location) (string, error) {
+	schedules, err := c.ConvertToSchedules(expr, base)
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, 0, len(schedules))
+	for _, s := range schedules {
+		lines = append(lines, s.String())
 	}
+	return strings.Join(lines, "\n"), nil
 }
 
-func (c *Converter) convertRate(scheduleExpression string) (string, error) {
-	parts := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(scheduleExpression, "rate("), ")"))
-	if len(parts) != 2 {
-		return "", errors.New("invalid format: require rate(Value Unit) ")
+// ConvertToSchedules converts a parsed schedule expression evaluated in the given location into crontab schedules.
+func (c *Converter) ConvertToSchedules(expr Expression, base *time.Location) ([]*Schedule, error) {
+	if c.TimeZone == nil {
+		c.TimeZone = time.Local
 	}
-	value, err := strconv.ParseUint(parts[0], 10, 32)
+	if base == nil {
+		base = time.UTC
+	}
+	schedules, err := c.convertToSchedules(expr, base)
 	if err != nil {
-		return "", fmt.Errorf("invalid format: parse Value: %w", err)
-	}
-	if value == 0 {
-		return "", errors.New("invalid format: require Value over 0")
-	}
-	unit := parts[1]
-	if value == 1 {
-		switch unit {
-		case "minute":
-			s := &Schedule{
-				Minute:     "*",
-				Hour:       "*",
-				DayOfMonth: "*",
-				Month:      "*",
-				DayOfWeek:  "*",
-			}
-			return s.String(), nil
-		case "hour":
-			s := &Schedule{
-				Minute:     "0",
-				Hour:       "*",
-				DayOfMonth: "*",
-				Month:      "*",
-				DayOfWeek:  "*",
-			}
-			return s.String(), nil
-		case "day":
-			s := &Schedule{
-				Minute:     "0",
-				Hour:       fmt.Sprintf("%d", convertTimeZone(0, c.ReferenceDate.Location(), c.TimeZone)),
-				DayOfMonth: "*",
-				Month:      "*",
-				DayOfWeek:  "*",
-			}
-			return s.String(), nil
-		case "minutes":
-			return "", errors.New("invalid format: can not use pluralistic")
-		case "hours":
-			return "", errors.New("invalid format: can not use pluralistic")
-		case "days":
-			return "", errors.New("invalid format: can not use pluralistic")
-		default:
-			return "", fmt.Errorf("invalid format: unknown unit: %s", unit)
-		}
+		return nil, err
 	}
-	switch unit {
-	case "minute":
-		return "", errors.New("invalid format: can not use singular form")
-	case "hour":
-		return "", errors.New("invalid format: can not use singular form")
-	case "day":
-		return "", errors.New("invalid format: can not use singular form")
-	case "minutes":
-		s := &Schedule{
-			Minute:     fmt.Sprintf("*/%d", value),
-			Hour:       "*",
-			DayOfMonth: "*",
-			Month:      "*",
-			DayOfWeek:  "*",
+	if c.Strict {
+		if err := c.checkLossless(expr, base); err != nil {
+			return nil, err
 		}
-		return s.String(), nil
-	case "hours":
-		s := &Schedule{
-			Minute:     "0",
-			Hour:       fmt.Sprintf("*/%d", value),
-			DayOfMonth: "*",
-			Month:      "*",
-			DayOfWeek:  "*",
+	}
+	return schedules, nil
+}
+
+func (c *Converter) convertToSchedules(expr Expression, base *time.Location) ([]*Schedule, error) {
+	switch e := expr.(type) {
+	case *RateExpression:
+		s, err := c.convertRate(e, base)
+		if err != nil {
+			return nil, err
 		}
-		return s.String(), nil
-	case "days":
-		s := &Schedule{
-			Minute:     "0",
-			Hour:       fmt.Sprintf("%d", convertTimeZone(0, c.ReferenceDate.Location(), c.TimeZone)),
-			DayOfMonth: fmt.Sprintf("*/%d", value),
-			Month:      "*",
-			DayOfWeek:  "*",
+		return []*Schedule{s}, nil
+	case *CronExpression:
+		return c.convertCron(e, base)
+	case *AtExpression:
+		s, err := c.convertAt(e, base)
+		if err != nil {
+			return nil, err
 		}
-		return s.String(), nil
+		return []*Schedule{s}, nil
 	default:
-		return "", fmt.Errorf("invalid format: unknown unit: %s", unit)
+		return nil, newUnsupportedError(expr, "invalid format: unknown expression %T", expr)
 	}
 }
 
-func (c *Converter) convertCron(scheduleExpression string) (string, error) {
-	parts := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(scheduleExpression, "cron("), ")"))
-	if len(parts) != 6 {
-		return "", errors.New("invalid format: require cron(Minutes Hours Day-of-month Month Day-of-week Year) ")
+// CheckLossless returns *LossyConversionError if the crontab can not represent the expression exactly.
+// The expression is evaluated in UTC.
+func (c *Converter) CheckLossless(expr Expression) error {
+	return c.checkLossless(expr, time.UTC)
+}
+
+func (c *Converter) checkLossless(expr Expression, base *time.Location) error {
+	switch e := expr.(type) {
+	case *RateExpression:
+		return checkRateLossless(e, "crontab")
+	case *CronExpression:
+		if err := c.checkSpecialItemsLossless(e); err != nil {
+			return err
+		}
+		if !e.Year.IsAny() {
+			return newLossyConversionError(e, "the year field %s is not supported in crontab", e.Year)
+		}
+		if err := c.checkCarryLossless(e, base); err != nil {
+			return err
+		}
 	}
-	minute := parts[0]
-	if minute == "?" {
-		minute = "*"
+	return nil
+}
+
+// checkCarryLossless checks the day-of-month carried over by the time zone.
+// When the month is not restricted, the days across the last day of the month depend on the number of days of each month.
+func (c *Converter) checkCarryLossless(e *CronExpression, base *time.Location) error {
+	pinned := c.pinMonth && (e.DayOfMonth.HasSpecialItem() || e.DayOfWeek.HasSpecialItem())
+	if pinned || !e.Month.IsAny() || e.DayOfMonth.IsAny() {
+		return nil
 	}
-	hour, err := convertCronHourPartTimeZone(parts[1], c.ReferenceDate.Location(), c.TimeZone)
-	if err != nil {
-		return "", err
+	if base == nil {
+		base = time.UTC
 	}
-	dayOfMonth := parts[2]
-	if dayOfMonth == "?" {
-		dayOfMonth = "*"
+	to := c.TimeZone
+	if to == nil {
+		to = time.Local
 	}
-	if strings.ContainsRune(dayOfMonth, 'W') {
-		value, err := strconv.ParseUint(strings.TrimRight(dayOfMonth, "W"), 10, 32)
-		if err != nil {
-			return "", fmt.Errorf("invalid format: parse day of month: %w", err)
+	values := func(f *CronField) []bool {
+		values := make([]bool, f.Kind.Max()-f.Kind.Min()+1)
+		for i := range values {
+			values[i] = f.Match(f.Kind.Min() + i)
 		}
-		date := time.Date(c.ReferenceDate.Year(), c.ReferenceDate.Month(), int(value), 0, 0, 0, 0, c.ReferenceDate.Location())
-		for date.Weekday() == time.Sunday || date.Weekday() == time.Saturday {
-			date = date.AddDate(0, 0, -1)
-		}
-		dayOfMonth = fmt.Sprintf("%d", date.Day())
+		return values
 	}
-	if dayOfMonth == "L" {
-		dayOfMonth = fmt.Sprintf("%d", c.ReferenceDate.AddDate(0, 1, 0).AddDate(0, 0, -1).Day())
+	offsetMinutes := floorDiv(timeZoneOffset(c.ReferenceDate, base, to), 60)
+	if day := monthEndCarriedDay(values(e.Minutes), values(e.Hours), values(e.DayOfMonth), offsetMinutes, false); day > 0 {
+		return newLossyConversionError(e, "the day %d carried over by the time zone depends on the number of days of the month", day)
 	}
-	month := parts[3]
-	if month == "?" {
-		month = "*"
+	return nil
+}
+
+// checkRateLossless checks the rate which restarts at the boundary of the larger unit in the target notation.
+func checkRateLossless(e *RateExpression, target string) error {
+	switch {
+	case e.Unit == RateUnitMinutes && 60%e.Value != 0:
+		return newLossyConversionError(e, "%s restarts every hour in %s", e, target)
+	case e.Unit == RateUnitHours && 24%e.Value != 0:
+		return newLossyConversionError(e, "%s restarts every day in %s", e, target)
+	case e.Unit == RateUnitDays && e.Value > 1:
+		return newLossyConversionError(e, "%s restarts every month in %s", e, target)
 	}
-	month = convertCronMonthPart(month)
-	dayOfWeek := parts[4]
-	if dayOfWeek == "?" {
-		dayOfWeek = "*"
+	return nil
+}
+
+func (c *Converter) checkSpecialItemsLossless(e *CronExpression) error {
+	if !c.pinMonth && (e.DayOfMonth.HasSpecialItem() || e.DayOfWeek.HasSpecialItem()) {
+		return newLossyConversionError(e, "L, W and # are resolved in the month of the reference date")
 	}
-	if strings.ContainsRune(dayOfWeek, '#') {
-		p := strings.SplitAfterN(dayOfWeek, "#", 2)
-		if len(p) != 2 {
-			return "", fmt.Errorf("invalid format: parse day of week syntax: %s", dayOfWeek)
-		}
-		value, err := strconv.ParseUint(strings.TrimRight(p[0], "#"), 10, 32)
-		if err != nil {
-			return "", fmt.Errorf("invalid format: parse day of week value: %w", err)
-		}
-		weekday := time.Weekday(value - 1)
-		count, err := strconv.ParseUint(p[1], 10, 32)
-		if err != nil {
-			return "", fmt.Errorf("invalid format: parse day of week count: %w", err)
-		}
-		date := time.Date(c.ReferenceDate.Year(), c.ReferenceDate.Month(), 1, 0, 0, 0, 0, c.ReferenceDate.Location())
-		i := uint64(0)
-		for date.Month() == c.ReferenceDate.Month() {
-			if date.Weekday() != weekday {
-				date = date.AddDate(0, 0, 1)
-				continue
-			}
-			i++
-			if i == count {
-				break
-			}
-			date = date.AddDate(0, 0, 7)
-		}
-		dayOfMonth = fmt.Sprintf("%d", date.Day())
-		dayOfWeek = "*"
+	return nil
+}
+
+func (c *Converter) convertRate(e *RateExpression, base *time.Location) (*Schedule, error) {
+	s := &Schedule{
+		Minute:     "*",
+		Hour:       "*",
+		DayOfMonth: "*",
+		Month:      "*",
+		DayOfWeek:  "*",
+	}
+	offsetMinutes := floorDiv(timeZoneOffset(c.ReferenceDate, base, c.TimeZone), 60)
+	every := "*"
+	if e.Value != 1 {
+		every = fmt.Sprintf("*/%d", e.Value)
 	}
-	if strings.ContainsRune(dayOfWeek, 'L') {
-		value, err := strconv.ParseUint(strings.TrimRight(dayOfWeek, "L"), 10, 32)
+	switch e.Unit {
+	case RateUnitMinutes:
+		s.Minute = every
+	case RateUnitHours:
+		s.Minute = fmt.Sprintf("%d", modulo(offsetMinutes, 60))
+		s.Hour = every
+	case RateUnitDays:
+		s.Minute = fmt.Sprintf("%d", modulo(offsetMinutes, 60))
+		s.Hour = fmt.Sprintf("%d", modulo(floorDiv(offsetMinutes, 60), 24))
+		s.DayOfMonth = every
+	default:
+		return nil, newUnsupportedError(e, "invalid format: unknown unit: %s", e.Unit)
+	}
+	return s, nil
+}
+
+func (c *Converter) convertCron(e *CronExpression, base *time.Location) ([]*Schedule, error) {
+	if !e.Year.Match(c.ReferenceDate.Year()) {
+		return nil, newOutOfTargetError(e, "cannot be converted because the reference date is not the target year: %s", e.Year)
+	}
+	dayOfMonth, err := c.resolveDayOfMonth(e)
+	if err != nil {
+		return nil, err
+	}
+	dayOfWeek := crontabDayOfWeekItems(e.DayOfWeek.Items)
+	if e.DayOfWeek.HasSpecialItem() {
+		dayOfMonth, err = c.resolveDayOfWeek(e)
 		if err != nil {
-			return "", fmt.Errorf("invalid format: parse day of week: %w", err)
+			return nil, err
 		}
-		weekday := time.Weekday(value - 1)
-		date := c.ReferenceDate.AddDate(0, 1, 0).AddDate(0, 0, -1)
-		for date.Weekday() != weekday {
-			date = date.AddDate(0, 0, -1)
-		}
-		dayOfMonth = fmt.Sprintf("%d", date.Day())
-		dayOfWeek = "*"
+		dayOfWeek = []*CronItem{{Kind: CronItemAll}}
 	}
-	dayOfWeek = convertCronDayOfWeekPart(dayOfWeek)
+	f := &crontabFields{
+		minute:     splitWrappedItems(crontabItems(e.Minutes.Items), 0, 59),
+		hour:       splitWrappedItems(crontabItems(e.Hours.Items), 0, 23),
+		dayOfMonth: dayOfMonth,
+		month:      splitWrappedItems(crontabItems(e.Month.Items), 1, 12),
+		dayOfWeek:  dayOfWeek,
 
-	year := parts[5]
-	isTarget, err := referenceDateIsTargetYear(c.ReferenceDate, year)
-	if err != nil {
-		return "", err
+		resolvedInMonth: e.DayOfMonth.HasSpecialItem() || e.DayOfWeek.HasSpecialItem(),
+	}
+	if c.pinMonth {
+		f.month = []*CronItem{{Kind: CronItemValue, Start: int(c.ReferenceDate.Month())}}
 	}
-	if !isTarget {
-		return "", fmt.Errorf("cannot be converted because the reference date is not the target year: %s", year)
+	fields := c.shiftTimeZone(f, timeZoneOffset(c.ReferenceDate, base, c.TimeZone))
+	schedules := make([]*Schedule, 0, len(fields))
+	for _, f := range fields {
+		schedules = append(schedules, f.schedule())
+	}
+	return schedules, nil
+}
+
+func (c *Converter) convertAt(e *AtExpression, base *time.Location) (*Schedule, error) {
+	t := e.Time(base).In(c.TimeZone)
+	if t.Year() != c.ReferenceDate.Year() || t.Month() != c.ReferenceDate.Month() {
+		return nil, newOutOfTargetError(e, "cannot be converted because the reference date is not the target month: %s", e.Time(base).Format(atLayout))
 	}
 	s := &Schedule{
-		Minute:     minute,
-		Hour:       hour,
-		DayOfMonth: dayOfMonth,
-		Month:      month,
-		DayOfWeek:  dayOfWeek,
+		Minute:     fmt.Sprintf("%d", t.Minute()),
+		Hour:       fmt.Sprintf("%d", t.Hour()),
+		DayOfMonth: fmt.Sprintf("%d", t.Day()),
+		Month:      fmt.Sprintf("%d", int(t.Month())),
+		DayOfWeek:  "*",
 	}
-	return s.String(), nil
+	return s, nil
 }
 
-func convertTimeZone(value uint64, base *time.Location, to *time.Location) uint64 {
-	return uint64(time.Date(2022, 06, 01, int(value), 0, 0, 0, base).In(to).Hour())
+// resolveDayOfMonth resolves L, LW and W items of the day-of-month in the reference month.
+func (c *Converter) resolveDayOfMonth(e *CronExpression) ([]*CronItem, error) {
+	f := e.DayOfMonth
+	year, month := c.ReferenceDate.Year(), c.ReferenceDate.Month()
+	items := make([]*CronItem, 0, len(f.Items))
+	for _, item := range f.Items {
+		switch item.Kind {
+		case CronItemLast:
+			items = append(items, &CronItem{Kind: CronItemValue, Start: lastDayOfMonth(year, month)})
+		case CronItemLastWeekday:
+			items = append(items, &CronItem{Kind: CronItemValue, Start: nearestWeekday(year, month, lastDayOfMonth(year, month))})
+		case CronItemWeekday:
+			if item.Start > lastDayOfMonth(year, month) {
+				return nil, newOutOfTargetError(e, "cannot be converted because the reference month has no %s", item)
+			}
+			items = append(items, &CronItem{Kind: CronItemValue, Start: nearestWeekday(year, month, item.Start)})
+		default:
+			items = append(items, splitWrappedItems(crontabItems([]*CronItem{item}), 1, 31)...)
+		}
+	}
+	return items, nil
 }
 
-func convertCronDayOfWeekPart(dayOfWeek string) string {
-	dayOfWeek = strings.ToUpper(dayOfWeek)
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "1", "0")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "2", "1")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "3", "2")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "4", "3")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "5", "4")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "6", "5")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "7", "6")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "MON", "1")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "TUE", "2")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "WED", "3")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "THU", "4")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "FRI", "5")
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "SAT", "6")
-	if strings.ContainsRune(dayOfWeek, '-') {
-		parts := strings.SplitN(dayOfWeek, "-", 2)
-		if len(parts) == 2 {
-			parts[1] = strings.ReplaceAll(parts[1], "SUN", "7")
+// resolveDayOfWeek resolves nL and n#k items of the day-of-week into the day-of-month in the reference month.
+func (c *Converter) resolveDayOfWeek(e *CronExpression) ([]*CronItem, error) {
+	f := e.DayOfWeek
+	year, month := c.ReferenceDate.Year(), c.ReferenceDate.Month()
+	items := make([]*CronItem, 0, len(f.Items))
+	for _, item := range f.Items {
+		weekday := time.Weekday(item.Start - 1)
+		switch item.Kind {
+		case CronItemLast:
+			items = append(items, &CronItem{Kind: CronItemValue, Start: lastWeekdayOfMonth(year, month, weekday)})
+		case CronItemNth:
+			day, ok := nthWeekdayOfMonth(year, month, weekday, item.Nth)
+			if !ok {
+				return nil, newOutOfTargetError(e, "cannot be converted because the reference month has no %s", item)
+			}
+			items = append(items, &CronItem{Kind: CronItemValue, Start: day})
+		default:
+			return nil, newUnsupportedError(e, "cannot be converted because the day-of-week mixes %s with other values", item)
 		}
-		dayOfWeek = strings.Join(parts, "-")
 	}
-	dayOfWeek = strings.ReplaceAll(dayOfWeek, "SUN", "0")
-	return dayOfWeek
+	return items, nil
 }
 
-func convertCronMonthPart(month string) string {
-	month = strings.ToUpper(month)
-	month = strings.ReplaceAll(month, "JAN", "1")
-	month = strings.ReplaceAll(month, "FEB", "2")
-	month = strings.ReplaceAll(month, "MAR", "3")
-	month = strings.ReplaceAll(month, "APR", "4")
-	month = strings.ReplaceAll(month, "MAY", "5")
-	month = strings.ReplaceAll(month, "JUN", "6")
-	month = strings.ReplaceAll(month, "JUL", "7")
-	month = strings.ReplaceAll(month, "AUG", "8")
-	month = strings.ReplaceAll(month, "SEP", "9")
-	month = strings.ReplaceAll(month, "OCT", "10")
-	month = strings.ReplaceAll(month, "NOV", "11")
-	month = strings.ReplaceAll(month, "DEC", "12")
-	return month
+// crontabItems converts plain items into crontab's one, `?` means `*`.
+func crontabItems(items []*CronItem) []*CronItem {
+	ret := make([]*CronItem, 0, len(items))
+	for _, item := range items {
+		converted := *item
+		if converted.Kind == CronItemNoSpecific {
+			converted.Kind = CronItemAll
+		}
+		ret = append(ret, &converted)
+	}
+	return ret
 }
 
-func convertCronHourPartTimeZone(hour string, base *time.Location, to *time.Location) (string, error) {
-	var hourRate string
-	if strings.ContainsRune(hour, '/') {
-		p := strings.SplitAfterN(hour, "/", 2)
-		if len(p) != 2 {
-			return "", fmt.Errorf("invalid format: parse hour syntax: %s", hour)
+// splitWrappedItems splits wrapping ranges such as 22-2 into the ranges which crontab accepts, 22-23,0-2.
+// The values after the wrap keep the offset of the step from the start.
+func splitWrappedItems(items []*CronItem, min, max int) []*CronItem {
+	ret := make([]*CronItem, 0, len(items))
+	for _, item := range items {
+		if item.Kind != CronItemRange || item.Start <= item.End {
+			ret = append(ret, item)
+			continue
 		}
-		hour = strings.TrimRight(p[0], "/")
-		hourRate = "/" + p[1]
-	}
-	hours := strings.Split(hour, ",")
-	afterHours := make([]string, 0, len(hours))
-	for _, hour := range hours {
-		switch {
-		case hour == "*" || hour == "?":
-			afterHours = append(afterHours, "*")
-		case strings.ContainsRune(hour, '-'):
-			p := strings.SplitAfterN(hour, "-", 2)
-			if len(p) != 2 {
-				return "", fmt.Errorf("invalid format: parse hour syntax: %s", hour)
-			}
-			start, err := strconv.ParseUint(strings.TrimRight(p[0], "-"), 10, 32)
-			if err != nil {
-				return "", fmt.Errorf("invalid format: parse hour start: %w", err)
-			}
-			start = convertTimeZone(start, base, to)
-			end, err := strconv.ParseUint(p[1], 10, 32)
-			if err != nil {
-				return "", fmt.Errorf("invalid format: parse hour start: %w", err)
-			}
-			end = convertTimeZone(end, base, to)
-			afterHours = append(afterHours, fmt.Sprintf("%d-%d", start, end))
-		default:
-			value, err := strconv.ParseUint(hour, 10, 32)
-			if err != nil {
-				return "", fmt.Errorf("invalid format: parse hour: %w", err)
-			}
-			afterHours = append(afterHours, fmt.Sprintf("%d", convertTimeZone(value, base, to)))
+		step := item.Step
+		if step < 1 {
+			step = 1
+		}
+		last := item.Start + (max-item.Start)/step*step
+		ret = append(ret, newCrontabItem(item.Start, last, step, min, max))
+		first := last + step - (max - min + 1)
+		if first <= item.End {
+			ret = append(ret, newCrontabItem(first, first+(item.End-first)/step*step, step, min, max))
 		}
 	}
-	return strings.Join(afterHours, ",") + hourRate, nil
+	return ret
 }
 
-func referenceDateIsTargetYear(referenceDate time.Time, year string) (bool, error) {
-	var yearRate *uint64
-	if strings.ContainsRune(year, '/') {
-		p := strings.SplitAfterN(year, "/", 2)
-		if len(p) != 2 {
-			return false, fmt.Errorf("invalid format: parse year syntax: %s", year)
+// crontabDayOfWeekItems converts day-of-week items from 1 (SUN) - 7 (SAT) into 0 (SUN) - 6 (SAT).
+// A range ending with SUN such as TUE-SUN is converted to 2-7.
+func crontabDayOfWeekItems(items []*CronItem) []*CronItem {
+	ret := make([]*CronItem, 0, len(items))
+	for _, item := range crontabItems(items) {
+		switch item.Kind {
+		case CronItemValue:
+			item.Start--
+		case CronItemRange:
+			item.Start--
+			item.End--
+			if item.End < item.Start {
+				if item.End != 0 {
+					ret = append(ret, &CronItem{Kind: CronItemRange, Start: item.Start, End: 6, Step: item.Step})
+					item = &CronItem{Kind: CronItemRange, Start: 0, End: item.End, Step: item.Step}
+				} else {
+					item.End = 7
+				}
+			}
 		}
-		year = strings.TrimRight(p[0], "/")
-		value, err := strconv.ParseUint(p[1], 10, 32)
-		if err != nil {
-			return false, fmt.Errorf("invalid format: parse year rate: %w", err)
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+func lastDayOfMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the weekday nearest to the day, without crossing over the month.
+func nearestWeekday(year int, month time.Month, day int) int {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	switch date.Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
 		}
-		yearRate = &value
-	}
-	years := strings.Split(year, ",")
-	refYear := uint64(referenceDate.Year())
-	for _, y := range years {
-		switch {
-		case y == "*":
-			if yearRate == nil {
-				return true, nil
-			}
-			if refYear%*yearRate == 0 {
-				return true, nil
-			}
-		case strings.ContainsRune(y, '-'):
-			p := strings.SplitAfterN(y, "-", 2)
-			if len(p) != 2 {
-				return false, fmt.Errorf("invalid format: parse year syntax: %s", year)
-			}
-			start, err := strconv.ParseUint(strings.TrimRight(p[0], "-"), 10, 32)
-			if err != nil {
-				return false, fmt.Errorf("invalid format: parse year start: %w", err)
-			}
-			end, err := strconv.ParseUint(p[1], 10, 32)
-			if err != nil {
-				return false, fmt.Errorf("invalid format: parse year start: %w", err)
-			}
-			if refYear < start {
-				break
-			}
-			if refYear > end {
-				break
-			}
-			if yearRate == nil {
-				return true, nil
-			}
-			if refYear%*yearRate == 0 {
-				return true, nil
-			}
-		default:
-			value, err := strconv.ParseUint(y, 10, 32)
-			if err != nil {
-				return false, fmt.Errorf("invalid format: parse year: %w", err)
-			}
-			if value == refYear {
-				return true, nil
-			}
+		return day - 1
+	case time.Sunday:
+		if day == lastDayOfMonth(year, month) {
+			return day - 2
 		}
+		return day + 1
+	default:
+		return day
+	}
+}
+
+func lastWeekdayOfMonth(year int, month time.Month, weekday time.Weekday) int {
+	date := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	for date.Weekday() != weekday {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date.Day()
+}
+
+func nthWeekdayOfMonth(year int, month time.Month, weekday time.Weekday, nth int) (int, bool) {
+	date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	for date.Weekday() != weekday {
+		date = date.AddDate(0, 0, 1)
+	}
+	date = date.AddDate(0, 0, 7*(nth-1))
+	if date.Month() != month {
+		return 0, false
 	}
-	return false, nil
+	return date.Day(), true
 }
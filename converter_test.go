@@ -11,11 +11,12 @@ import (
 func TestConverter(t *testing.T) {
 	defaultReferenceDate := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
 	cases := []struct {
-		scheduleExpression string
-		referenceDate      time.Time
-		timeZone           *time.Location
-		expectedCrontab    string
-		expectedError      string
+		scheduleExpression         string
+		scheduleExpressionTimezone *time.Location
+		referenceDate              time.Time
+		timeZone                   *time.Location
+		expectedCrontab            string
+		expectedError              string
 	}{
 		//https://docs.aws.amazon.com/ja_jp/lambda/latest/dg/services-cloudwatchevents-expressions.html
 		{
@@ -137,6 +138,14 @@ func TestConverter(t *testing.T) {
 			scheduleExpression: "cron(0/10 * ? * 1-7 *)",
 			expectedCrontab:    "0/10 * * * 0-6",
 		},
+		{
+			scheduleExpression: "cron(0 18 ? * 1,7 *)",
+			expectedCrontab:    "0 18 * * 0,6",
+		},
+		{
+			scheduleExpression: "cron(0 18 ? * FRI-MON *)",
+			expectedCrontab:    "0 18 * * 5-6,0-1",
+		},
 		{
 			scheduleExpression: "cron(15 * 2W * ? *)",
 			expectedCrontab:    "15 * 1 * *",
@@ -162,6 +171,228 @@ func TestConverter(t *testing.T) {
 			expectedCrontab:    "15 * 12 * *",
 			referenceDate:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
 		},
+		{
+			scheduleExpression: "cron(15 * ? * 1#1,3#1 *)",
+			expectedCrontab:    "15 * 3,5 * *",
+			referenceDate:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			scheduleExpression: "cron(15 * ? * 3#5 *)",
+			expectedError:      "cannot be converted because the reference month has no 3#5",
+			referenceDate:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			scheduleExpression: "cron(15 * LW * ? *)",
+			expectedCrontab:    "15 * 29 * *",
+			referenceDate:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			scheduleExpression:         "cron(15 10 * * ? *)",
+			scheduleExpressionTimezone: Must(time.LoadLocation("Asia/Tokyo")),
+			expectedCrontab:            "15 1 * * *",
+		},
+		{
+			scheduleExpression:         "cron(15 10 * * ? *)",
+			scheduleExpressionTimezone: Must(time.LoadLocation("Asia/Tokyo")),
+			expectedCrontab:            "15 10 * * *",
+			timeZone:                   Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression:         "rate(1 day)",
+			scheduleExpressionTimezone: Must(time.LoadLocation("America/Los_Angeles")),
+			expectedCrontab:            "0 16 * * *",
+			timeZone:                   Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 18 ? * MON-FRI *)",
+			expectedCrontab:    "0 3 * * 2-6",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 10-16 ? * MON *)",
+			expectedCrontab:    "0 19-23 * * 1\n0 0-1 * * 2",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 2 ? * SUN,MON *)",
+			expectedCrontab:    "0 19 * * 0,6",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "cron(0 0 1,15 * ? *)",
+			expectedCrontab:    "0 17 31 * *\n0 17 14 * *",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "cron(0 0 1,15 * ? *)",
+			expectedCrontab:    "0 17 30 * *\n0 17 14 * *",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+			referenceDate:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			scheduleExpression: "cron(0 0 1 JAN ? *)",
+			expectedCrontab:    "0 17 31 12 *",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "cron(0 20 31 DEC ? *)",
+			expectedCrontab:    "0 5 1 1 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 * JAN ? *)",
+			expectedCrontab:    "0 5 2-31 1 *\n0 5 1 2 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 30 6 ? *)",
+			expectedCrontab:    "0 5 1 7 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 * JUN ? *)",
+			expectedCrontab:    "0 5 2-30 6 *\n0 5 1 7 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 30 APR,JUN,JUL ? *)",
+			expectedCrontab:    "0 5 1 5,7 *\n0 5 31 7 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 L * ? *)",
+			expectedCrontab:    "0 5 1 * *",
+			referenceDate:      time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 LW * ? *)",
+			expectedCrontab:    "0 5 1 * *",
+			referenceDate:      time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0/20 23 L * ? *)",
+			expectedCrontab:    "30-50/20 4 1 * *\n10 5 1 * *",
+			referenceDate:      time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0 1 1 MAR,MAY ? *)",
+			expectedCrontab:    "0 18 28 2 *\n0 18 30 4 *",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "cron(50-10 1 * * ? *)",
+			expectedCrontab:    "30-40 6 * * *\n20-29 7 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0 1 30-2 * ? *)",
+			expectedCrontab:    "0 1 30-31,1-2 * *",
+			timeZone:           time.UTC,
+		},
+		{
+			scheduleExpression: "cron(0 22-2/2 ? * FRI-MON *)",
+			expectedCrontab:    "30 5-7/2 * * 5-6,0-1\n30 3 * * 0-2,6",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0 1 * NOV-FEB ? *)",
+			expectedCrontab:    "0 1 * 11-12,1-2 *",
+			timeZone:           time.UTC,
+		},
+		{
+			scheduleExpression: "cron(0 1 * NOV-FEB ? *)",
+			expectedCrontab:    "0 18 31 1,10,12 *\n0 18 1-30 1,12 *\n0 18 1-27 2 *\n0 18 1-29 11 *\n0 18 30 11 *",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "cron(0 20 * DEC-JAN ? *)",
+			expectedCrontab:    "0 5 2-31 12,1 *\n0 5 1 1-2 *",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "cron(0 20 ? * 6L *)",
+			expectedCrontab:    "0 5 25 * *",
+			referenceDate:      time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression: "rate(1 day)",
+			expectedCrontab:    "30 5 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "rate(1 hour)",
+			expectedCrontab:    "30 * * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(15 10 * * ? *)",
+			expectedCrontab:    "45 15 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(45 10 * * ? *)",
+			expectedCrontab:    "15 16 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0/15 10 * * ? *)",
+			expectedCrontab:    "30-45/15 15 * * *\n0-15/15 16 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(10-40 10,12 * * ? *)",
+			expectedCrontab:    "40-59 15,17 * * *\n0-10 16,18 * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0/15 * * * ? *)",
+			expectedCrontab:    "*/15 * * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(5,20 * * * ? *)",
+			expectedCrontab:    "35,50 * * * *",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(30 18 ? * MON-FRI *)",
+			expectedCrontab:    "0 0 * * 2-6",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+		},
+		{
+			scheduleExpression: "cron(0 15 * * ? *)",
+			expectedCrontab:    "30 0 * * *",
+			timeZone:           Must(time.LoadLocation("Australia/Adelaide")),
+		},
+		{
+			scheduleExpression: "cron(0 12 * * ? *)",
+			expectedCrontab:    "30 9 * * *",
+			timeZone:           Must(time.LoadLocation("America/St_Johns")),
+		},
+		{
+			scheduleExpression: "cron(0 12 * * ? *)",
+			expectedCrontab:    "0 4 * * *",
+			referenceDate:      time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC),
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+		},
+		{
+			scheduleExpression: "at(2022-06-15T10:30:00)",
+			expectedCrontab:    "30 10 15 6 *",
+		},
+		{
+			scheduleExpression: "at(2022-06-30T20:00:00)",
+			expectedError:      "cannot be converted because the reference date is not the target month: 2022-06-30T20:00:00",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+		},
+		{
+			scheduleExpression:         "at(2022-07-01T08:00:00)",
+			scheduleExpressionTimezone: Must(time.LoadLocation("Asia/Tokyo")),
+			expectedCrontab:            "0 23 30 6 *",
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.scheduleExpression, func(t *testing.T) {
@@ -175,7 +406,13 @@ func TestConverter(t *testing.T) {
 				ReferenceDate: c.referenceDate,
 				TimeZone:      c.timeZone,
 			}
-			actual, err := converter.Convert(c.scheduleExpression)
+			var actual string
+			var err error
+			if c.scheduleExpressionTimezone == nil {
+				actual, err = converter.Convert(c.scheduleExpression)
+			} else {
+				actual, err = converter.ConvertInLocation(c.scheduleExpression, c.scheduleExpressionTimezone)
+			}
 			if c.expectedError == "" {
 				require.NoError(t, err)
 			} else {
@@ -188,6 +425,78 @@ func TestConverter(t *testing.T) {
 	}
 }
 
+func TestConverterHourWraparound(t *testing.T) {
+	tokyo := Must(time.LoadLocation("Asia/Tokyo"))
+	losAngeles := Must(time.LoadLocation("America/Los_Angeles"))
+	cases := []struct {
+		hours           string
+		timeZone        *time.Location
+		expectedCrontab string
+	}{
+		{hours: "*", timeZone: tokyo, expectedCrontab: "0 * * * *"},
+		{hours: "13-16", timeZone: tokyo, expectedCrontab: "0 22-23,0-1 * * *"},
+		{hours: "14-16", timeZone: tokyo, expectedCrontab: "0 23,0-1 * * *"},
+		{hours: "15-16", timeZone: tokyo, expectedCrontab: "0 0-1 * * *"},
+		{hours: "13-20/3", timeZone: tokyo, expectedCrontab: "0 22,1-4/3 * * *"},
+		{hours: "12-23/4", timeZone: tokyo, expectedCrontab: "0 21,1-5/4 * * *"},
+		{hours: "10/4", timeZone: tokyo, expectedCrontab: "0 19-23/4,3-7/4 * * *"},
+		{hours: "*/2", timeZone: tokyo, expectedCrontab: "0 1-23/2 * * *"},
+		{hours: "*/3", timeZone: tokyo, expectedCrontab: "0 */3 * * *"},
+		{hours: "0,20-23", timeZone: tokyo, expectedCrontab: "0 9,5-8 * * *"},
+		{hours: "22-2", timeZone: time.UTC, expectedCrontab: "0 22-23,0-2 * * *"},
+		{hours: "22-2", timeZone: tokyo, expectedCrontab: "0 7-8,9-11 * * *"},
+		{hours: "21-3/2", timeZone: time.UTC, expectedCrontab: "0 21-23/2,1-3/2 * * *"},
+		{hours: "19-1/3", timeZone: tokyo, expectedCrontab: "0 4-7/3,10 * * *"},
+		{hours: "5-10", timeZone: losAngeles, expectedCrontab: "0 22-23,0-3 * * *"},
+		{hours: "0-3", timeZone: losAngeles, expectedCrontab: "0 17-20 * * *"},
+	}
+	for _, c := range cases {
+		t.Run(c.hours+" "+c.timeZone.String(), func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      c.timeZone,
+			}
+			actual, err := converter.Convert("cron(0 " + c.hours + " * * ? *)")
+			require.NoError(t, err)
+			require.EqualValues(t, c.expectedCrontab, actual)
+		})
+	}
+}
+
+func TestConverterCarryLossless(t *testing.T) {
+	tokyo := Must(time.LoadLocation("Asia/Tokyo"))
+	losAngeles := Must(time.LoadLocation("America/Los_Angeles"))
+	cases := []struct {
+		scheduleExpression string
+		timeZone           *time.Location
+		lossy              bool
+	}{
+		{scheduleExpression: "cron(0 20 30 * ? *)", timeZone: tokyo, lossy: true},
+		{scheduleExpression: "cron(0 20 27 * ? *)", timeZone: tokyo, lossy: false},
+		{scheduleExpression: "cron(0 10 30 * ? *)", timeZone: tokyo, lossy: false},
+		{scheduleExpression: "cron(0 20 30 1,3 ? *)", timeZone: tokyo, lossy: false},
+		{scheduleExpression: "cron(0 0 1,15 * ? *)", timeZone: losAngeles, lossy: true},
+		{scheduleExpression: "cron(0 0 29 * ? *)", timeZone: losAngeles, lossy: true},
+		{scheduleExpression: "cron(0 0 2-28 * ? *)", timeZone: losAngeles, lossy: false},
+		{scheduleExpression: "cron(0 0 ? * MON *)", timeZone: losAngeles, lossy: false},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression+" "+c.timeZone.String(), func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      c.timeZone,
+				Strict:        true,
+			}
+			_, err := converter.Convert(c.scheduleExpression)
+			if !c.lossy {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, rules2cron.ErrorCodeLossy, rules2cron.CodeOf(err))
+		})
+	}
+}
+
 func Must[T any](t T, err error) T {
 	if err != nil {
 		panic(err)
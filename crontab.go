@@ -0,0 +1,327 @@
+package rules2cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// crontabMacros is the macros of crontab, @reboot is not a schedule.
+var crontabMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// CrontabLine is a schedule line of crontab.
+type CrontabLine struct {
+	// Line is the line number, starting from 1.
+	Line     int
+	Schedule string
+	Command  string
+}
+
+// ParseCrontab reads the schedule lines of crontab, skipping the empty lines, the comments and the environment settings.
+func ParseCrontab(r io.Reader) ([]*CrontabLine, error) {
+	lines := make([]*CrontabLine, 0)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if strings.HasPrefix(fields[0], "@") {
+			lines = append(lines, &CrontabLine{
+				Line:     n,
+				Schedule: fields[0],
+				Command:  strings.TrimSpace(strings.TrimPrefix(text, fields[0])),
+			})
+			continue
+		}
+		if eq := strings.Index(fields[0], "="); eq > 0 {
+			// environment setting such as MAILTO=""
+			continue
+		}
+		if len(fields) < 5 {
+			lines = append(lines, &CrontabLine{Line: n, Schedule: text})
+			continue
+		}
+		rest := text
+		for i := 0; i < 5; i++ {
+			rest = strings.TrimSpace(rest)
+			rest = rest[len(fields[i]):]
+		}
+		lines = append(lines, &CrontabLine{
+			Line:     n,
+			Schedule: strings.Join(fields[:5], " "),
+			Command:  strings.TrimSpace(rest),
+		})
+	}
+	return lines, scanner.Err()
+}
+
+var (
+	crontabMonthNames     = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	crontabDayOfWeekNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// ConvertFromCrontab converts a crontab schedule in TimeZone of the converter into cron() expressions of EventBridge in UTC.
+// It is the inverse of Convert, and the schedule may be converted into multiple expressions when it straddles midnight in UTC.
+// The UTC offset is calculated at ReferenceDate.
+func (c *Converter) ConvertFromCrontab(schedule string) ([]*CronExpression, error) {
+	if c.TimeZone == nil {
+		c.TimeZone = time.Local
+	}
+	f, err := parseCrontabSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if c.Strict {
+		if err := c.CheckCrontabLossless(schedule); err != nil {
+			return nil, err
+		}
+	}
+	offset := timeZoneOffset(c.ReferenceDate, c.TimeZone, time.UTC)
+	if isAllItems(f.month) && !isAllItems(f.dayOfMonth) {
+		if day := monthEndCarriedDay(itemValues(f.minute, 0, 59), itemValues(f.hour, 0, 23), itemValues(f.dayOfMonth, 1, 31), floorDiv(offset, 60), true); day > 0 {
+			return nil, &LossyConversionError{
+				Expression: schedule,
+				Message:    fmt.Sprintf("the day %d carried over into UTC depends on the number of days of the month", day),
+			}
+		}
+	}
+	reverse := *c
+	reverse.lastDayItem = true
+	shifted := reverse.shiftTimeZone(f, offset)
+	exprs := make([]*CronExpression, 0, len(shifted))
+	for _, s := range shifted {
+		dayOfMonth, dayOfWeek := formatEventBridgeField(s.dayOfMonth, 1), formatEventBridgeField(eventBridgeDayOfWeekItems(s.dayOfWeek), 1)
+		switch {
+		case isAllItems(s.dayOfWeek):
+			dayOfWeek = "?"
+		case isAllItems(s.dayOfMonth):
+			dayOfMonth = "?"
+		default:
+			return nil, &UnsupportedError{
+				Expression: schedule,
+				Message:    "cannot be converted because crontab fires when either the day-of-month or the day-of-week matches, but EventBridge requires ? for either of them",
+			}
+		}
+		text := fmt.Sprintf("cron(%s %s %s %s %s *)",
+			formatEventBridgeField(s.minute, 0),
+			formatEventBridgeField(s.hour, 0),
+			dayOfMonth,
+			formatEventBridgeField(s.month, 1),
+			dayOfWeek,
+		)
+		expr, err := ParseCronExpression(text)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+// CheckCrontabLossless returns *LossyConversionError if the UTC offset of TimeZone changes in the year of ReferenceDate,
+// since cron() expressions of EventBridge are fixed in UTC.
+func (c *Converter) CheckCrontabLossless(schedule string) error {
+	year := c.ReferenceDate.Year()
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, c.TimeZone).Zone()
+	_, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, c.TimeZone).Zone()
+	if winter != summer {
+		return &LossyConversionError{
+			Expression: schedule,
+			Message:    fmt.Sprintf("%s has the daylight saving time, the schedule shifts by the difference of the UTC offset in a part of the year", c.TimeZone),
+		}
+	}
+	return nil
+}
+
+// formatEventBridgeField formats the items in the syntax of EventBridge, the step of `*` is written from min such as `0/5`,
+// since EventBridge does not document `*/5`.
+func formatEventBridgeField(items []*CronItem, min int) string {
+	converted := make([]*CronItem, 0, len(items))
+	for _, item := range items {
+		if item.Kind == CronItemAll && item.Step > 1 {
+			item = &CronItem{Kind: CronItemValue, Start: min, Step: item.Step}
+		}
+		converted = append(converted, item)
+	}
+	return formatCrontabField(converted)
+}
+
+// eventBridgeDayOfWeekItems converts crontab's day-of-week items from 0 (SUN) - 6 (SAT) into 1 (SUN) - 7 (SAT).
+func eventBridgeDayOfWeekItems(items []*CronItem) []*CronItem {
+	ret := make([]*CronItem, 0, len(items))
+	for _, item := range items {
+		converted := *item
+		if converted.Kind != CronItemAll {
+			converted.Start++
+			converted.End++
+		}
+		ret = append(ret, &converted)
+	}
+	return ret
+}
+
+func parseCrontabSchedule(schedule string) (*crontabFields, error) {
+	text := strings.TrimSpace(schedule)
+	if strings.HasPrefix(text, "@") {
+		expanded, ok := crontabMacros[strings.ToLower(text)]
+		if !ok {
+			return nil, &UnsupportedError{Expression: schedule, Message: fmt.Sprintf("cannot be converted because %s is not a schedule", text)}
+		}
+		text = expanded
+	}
+	fields := strings.Fields(text)
+	if len(fields) != 5 {
+		return nil, &ParseError{Expression: schedule, Field: -1, Message: "require Minute Hour Day-of-month Month Day-of-week"}
+	}
+	f := &crontabFields{}
+	targets := []struct {
+		items    *[]*CronItem
+		min, max int
+		names    []string
+	}{
+		{items: &f.minute, min: 0, max: 59},
+		{items: &f.hour, min: 0, max: 23},
+		{items: &f.dayOfMonth, min: 1, max: 31},
+		{items: &f.month, min: 1, max: 12, names: crontabMonthNames},
+		{items: &f.dayOfWeek, min: 0, max: 7, names: crontabDayOfWeekNames},
+	}
+	for i, target := range targets {
+		items, err := parseCrontabField(fields[i], target.min, target.max, target.names)
+		if err != nil {
+			return nil, &ParseError{Expression: schedule, Field: i, Message: fmt.Sprintf("%s: %s", fields[i], err.Error())}
+		}
+		*target.items = items
+	}
+	f.dayOfWeek = normalizeCrontabDayOfWeek(f.dayOfWeek)
+	return f, nil
+}
+
+// parseCrontabField parses the field of crontab into the items, names are for the values from 1 for the month or from 0 for the day-of-week.
+func parseCrontabField(text string, min, max int, names []string) ([]*CronItem, error) {
+	parseValue := func(s string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(s, name) {
+				if min == 0 {
+					return i, nil
+				}
+				return i + 1, nil
+			}
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q", s)
+		}
+		if v < min || v > max {
+			return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+		}
+		return v, nil
+	}
+	items := make([]*CronItem, 0)
+	for _, part := range strings.Split(text, ",") {
+		item := &CronItem{Text: part}
+		body := part
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			step, err := strconv.Atoi(part[slash+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", part[slash+1:])
+			}
+			item.Step = step
+			body = part[:slash]
+		}
+		switch {
+		case body == "*":
+			item.Kind = CronItemAll
+		case strings.Contains(body, "-"):
+			bounds := strings.SplitN(body, "-", 2)
+			start, err := parseValue(bounds[0])
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseValue(bounds[1])
+			if err != nil {
+				return nil, err
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range %q", body)
+			}
+			item.Kind, item.Start, item.End = CronItemRange, start, end
+		default:
+			v, err := parseValue(body)
+			if err != nil {
+				return nil, err
+			}
+			item.Kind, item.Start = CronItemValue, v
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// normalizeCrontabDayOfWeek converts 7 (SUN) into 0.
+func normalizeCrontabDayOfWeek(items []*CronItem) []*CronItem {
+	if isAllItems(items) {
+		return []*CronItem{{Kind: CronItemAll}}
+	}
+	values := make([]bool, 7)
+	for _, item := range items {
+		start, end := crontabItemBounds(item, 0, 7)
+		step := item.Step
+		if step < 1 {
+			step = 1
+		}
+		for v := start; v <= end; v += step {
+			values[v%7] = true
+		}
+	}
+	return compressValues(values, 0, 6)
+}
+
+// ConvertCrontab converts the schedule lines of crontab read from r, and writes `cron()<TAB>command` lines into w.
+// The lines which can not be converted are written as comments, and reported with the name `line N`.
+func (c *Converter) ConvertCrontab(w io.Writer, r io.Reader) (*ConversionReport, error) {
+	lines, err := ParseCrontab(r)
+	if err != nil {
+		return nil, err
+	}
+	report := &ConversionReport{}
+	for _, line := range lines {
+		name := fmt.Sprintf("line %d", line.Line)
+		exprs, err := c.ConvertFromCrontab(line.Schedule)
+		if err != nil {
+			log.Printf("[warn] %s: [%s] %s", name, CodeOf(err), err.Error())
+			report.addSkipped(name, err)
+			if _, err := fmt.Fprintf(w, "# %s: %s\t%s\n", name, line.Schedule, line.Command); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !c.Strict {
+			// the lossy lines are skipped with Strict
+			if err := c.CheckCrontabLossless(line.Schedule); err != nil {
+				log.Printf("[warn] %s: %s", name, err.Error())
+			}
+		}
+		report.addConverted()
+		for _, expr := range exprs {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", expr, line.Command); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.log()
+	return report, nil
+}
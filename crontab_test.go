@@ -0,0 +1,132 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFromCrontab(t *testing.T) {
+	cases := []struct {
+		schedule          string
+		tz                string
+		expected          []string
+		expectedErrString string
+	}{
+		{schedule: "*/5 * * * *", tz: "UTC", expected: []string{"cron(0/5 * * * ? *)"}},
+		{schedule: "0 */6 */2 */3 *", tz: "UTC", expected: []string{"cron(0 0/6 1/2 1/3 ? *)"}},
+		{schedule: "0 0 * * */2", tz: "UTC", expected: []string{"cron(0 0 ? * 1/2 *)"}},
+		{schedule: "0 9 * * 1-5", tz: "Asia/Tokyo", expected: []string{"cron(0 0 ? * 2-6 *)"}},
+		{schedule: "0 1 * * MON-FRI", tz: "Asia/Tokyo", expected: []string{"cron(0 16 ? * 1-5 *)"}},
+		{schedule: "0 0 * * 5-7", tz: "UTC", expected: []string{"cron(0 0 ? * 1,6-7 *)"}},
+		{schedule: "30 8 1 jan *", tz: "Asia/Kolkata", expected: []string{"cron(0 3 1 1 ? *)"}},
+		{schedule: "0 3 1 * *", tz: "Asia/Tokyo", expected: []string{"cron(0 18 L * ? *)"}},
+		{schedule: "0 3 1 4 *", tz: "Asia/Tokyo", expected: []string{"cron(0 18 L 3 ? *)"}},
+		{schedule: "0 5 1 */2 *", tz: "Asia/Tokyo", expected: []string{"cron(0 20 L 2-12/2 ? *)"}},
+		{schedule: "0 20 27 * *", tz: "America/Los_Angeles", expected: []string{"cron(0 3 28 * ? *)"}},
+		{schedule: "0 20 30 1,3 *", tz: "America/Los_Angeles", expected: []string{"cron(0 3 31 1,3 ? *)"}},
+		{schedule: "0 20 30 * *", tz: "America/Los_Angeles", expectedErrString: "lossy conversion: the day 30 carried over into UTC depends on the number of days of the month"},
+		{schedule: "0 20 31 * *", tz: "America/Los_Angeles", expectedErrString: "lossy conversion: the day 31 carried over into UTC depends on the number of days of the month"},
+		{schedule: "0 3 31 * *", tz: "Asia/Tokyo", expectedErrString: "lossy conversion: the day 31 carried over into UTC depends on the number of days of the month"},
+		{schedule: "0 8-10 * * *", tz: "Asia/Tokyo", expected: []string{"cron(0 23,0-1 * * ? *)"}},
+		{schedule: "0 8-10 * * 1", tz: "Asia/Tokyo", expected: []string{"cron(0 23 ? * 1 *)", "cron(0 0-1 ? * 2 *)"}},
+		{schedule: "@daily", tz: "Asia/Tokyo", expected: []string{"cron(0 15 * * ? *)"}},
+		{schedule: "@weekly", tz: "Asia/Tokyo", expected: []string{"cron(0 15 ? * 7 *)"}},
+		{schedule: "@reboot", tz: "UTC", expectedErrString: "cannot be converted because @reboot is not a schedule"},
+		{schedule: "0 0 1 * 1", tz: "UTC", expectedErrString: "cannot be converted because crontab fires when either the day-of-month or the day-of-week matches, but EventBridge requires ? for either of them"},
+		{schedule: "0 0 * *", tz: "UTC", expectedErrString: "invalid format: require Minute Hour Day-of-month Month Day-of-week"},
+		{schedule: "0 24 * * *", tz: "UTC", expectedErrString: "invalid format: 24: value 24 out of range 0-23"},
+	}
+	for _, c := range cases {
+		t.Run(c.schedule+" "+c.tz, func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      Must(time.LoadLocation(c.tz)),
+			}
+			exprs, err := converter.ConvertFromCrontab(c.schedule)
+			if c.expectedErrString != "" {
+				require.EqualError(t, err, c.expectedErrString)
+				return
+			}
+			require.NoError(t, err)
+			actual := make([]string, 0, len(exprs))
+			for _, expr := range exprs {
+				actual = append(actual, expr.String())
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestConvertFromCrontabRoundTrip(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}
+	cases := []struct {
+		schedule string
+		expected string
+	}{
+		{schedule: "0 9 * * 1-5", expected: "0 9 * * 1-5"},
+		{schedule: "15 3 * * *", expected: "15 3 * * *"},
+		{schedule: "0 12 10 * *", expected: "0 12 10 * *"},
+		{schedule: "*/15 * * * *", expected: "0/15 * * * *"},
+		{schedule: "0 9 */5 */2 *", expected: "0 9 1/5 1/2 *"},
+	}
+	for _, c := range cases {
+		exprs, err := converter.ConvertFromCrontab(c.schedule)
+		require.NoError(t, err)
+		require.Len(t, exprs, 1)
+		// the expression is written in the syntax documented by EventBridge, the increments start from a value such as 0/15
+		require.Regexp(t, eventBridgeCronSyntax, exprs[0].String())
+		reparsed, err := rules2cron.ParseExpression(exprs[0].String())
+		require.NoError(t, err)
+		require.Equal(t, exprs[0].String(), reparsed.String())
+		actual, err := converter.ConvertExpression(reparsed, time.UTC)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, actual)
+	}
+}
+
+// eventBridgeCronSyntax is the syntax of cron() in the EventBridge documentation, `*`, `?`, the values, the ranges and the increments `a/b` separated by `,`.
+var eventBridgeCronSyntax = regexp.MustCompile(`^cron\(` + strings.Repeat(`(\*|\?|L|\d+(-\d+)?(/\d+)?)(,\d+(-\d+)?(/\d+)?)* `, 5) + `\*\)$`)
+
+func TestConvertFromCrontabStrict(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("America/New_York")),
+		Strict:        true,
+	}
+	_, err := converter.ConvertFromCrontab("0 9 * * *")
+	require.EqualError(t, err, "lossy conversion: America/New_York has the daylight saving time, the schedule shifts by the difference of the UTC offset in a part of the year")
+	require.Equal(t, rules2cron.ErrorCodeLossy, rules2cron.CodeOf(err))
+}
+
+func TestConvertCrontab(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}
+	crontab := strings.Join([]string{
+		`MAILTO=""`,
+		"# backup",
+		"0 9 * * 1-5   /usr/local/bin/backup --all",
+		"",
+		"@hourly /usr/local/bin/ping",
+		"@reboot /usr/local/bin/start",
+	}, "\n")
+	var buf bytes.Buffer
+	report, err := converter.ConvertCrontab(&buf, strings.NewReader(crontab))
+	require.NoError(t, err)
+	require.Equal(t, "cron(0 0 ? * 2-6 *)\t/usr/local/bin/backup --all\n"+
+		"cron(0 * * * ? *)\t/usr/local/bin/ping\n"+
+		"# line 6: @reboot\t/usr/local/bin/start\n", buf.String())
+	require.Equal(t, 2, report.Converted)
+	require.Len(t, report.Skipped, 1)
+	require.Equal(t, "line 6", report.Skipped[0].Name)
+}
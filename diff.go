@@ -0,0 +1,170 @@
+package rules2cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DriftKind is the kind of the difference between the declared rule and the live rule.
+type DriftKind string
+
+const (
+	// DriftAdded is the rule which exists only in the live rules, such as created in the console.
+	DriftAdded DriftKind = "added"
+	// DriftRemoved is the rule which is declared but does not exist in the live rules.
+	DriftRemoved DriftKind = "removed"
+	// DriftExpressionChanged is the rule whose schedule expression or time zone differs.
+	DriftExpressionChanged DriftKind = "expression_changed"
+	// DriftStateChanged is the rule whose state differs.
+	DriftStateChanged DriftKind = "state_changed"
+)
+
+// Drift is a difference between the declared rule and the live rule.
+// Declared and Live are the schedule expression or the state, with the time zone of the expression unless UTC.
+type Drift struct {
+	Name     string    `json:"name"`
+	Kind     DriftKind `json:"kind"`
+	Declared string    `json:"declared,omitempty"`
+	Live     string    `json:"live,omitempty"`
+}
+
+// Diff compares the declared rules with the live rules, matched by the event bus and the name.
+// The name of the declared rule is DeployedName if set, so the rules of the templates are matched with the names in AWS.
+// The declared rules which are Unnamed are skipped with a warning, since the names in AWS are unknown.
+// The account and the region are also matched if the declared rules have them, otherwise the live rules must be in an account and a region.
+// The drifts are named like TSV, such as `custom/name` for the event bus other than `default`, and sorted by the name.
+func Diff(ctx context.Context, declared, live Source) ([]*Drift, error) {
+	declaredRules, err := collectRules(ctx, declared, nil)
+	if err != nil {
+		return nil, err
+	}
+	located := false
+	for _, d := range declaredRules {
+		located = located || d.Account != "" || d.Region != ""
+	}
+	liveRules, err := collectRules(ctx, live, func(rule *Rule) string {
+		if located {
+			return diffKey(rule)
+		}
+		return qualifiedName("", "", rule.EventBusName, deployedName(rule))
+	})
+	if err != nil {
+		return nil, err
+	}
+	drifts := make([]*Drift, 0)
+	for name, d := range declaredRules {
+		l, ok := liveRules[name]
+		if !ok {
+			drifts = append(drifts, &Drift{Name: name, Kind: DriftRemoved, Declared: describeSchedule(d)})
+			continue
+		}
+		if describeSchedule(d) != describeSchedule(l) {
+			drifts = append(drifts, &Drift{Name: name, Kind: DriftExpressionChanged, Declared: describeSchedule(d), Live: describeSchedule(l)})
+		}
+		if d.State != l.State {
+			drifts = append(drifts, &Drift{Name: name, Kind: DriftStateChanged, Declared: d.State, Live: l.State})
+		}
+	}
+	for name, l := range liveRules {
+		if _, ok := declaredRules[name]; !ok {
+			drifts = append(drifts, &Drift{Name: name, Kind: DriftAdded, Live: describeSchedule(l)})
+		}
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].Name != drifts[j].Name {
+			return drifts[i].Name < drifts[j].Name
+		}
+		return drifts[i].Kind < drifts[j].Kind
+	})
+	return drifts, nil
+}
+
+// collectRules returns the rules of the source by the key, diffKey if nil, including the disabled rules.
+// It fails if the key is duplicated, such as the live rules of the same name in multiple regions which the declared rules do not distinguish.
+func collectRules(ctx context.Context, src Source, key func(*Rule) string) (map[string]*Rule, error) {
+	if key == nil {
+		key = diffKey
+	}
+	rules := make(map[string]*Rule)
+	err := src.Rules(ctx, func(rule *Rule) error {
+		if rule.Unnamed {
+			log.Printf("[warn] rule %s: skipped, since the name in AWS is generated on deploy, set the name to compare", rule.Name)
+			return nil
+		}
+		k := key(rule)
+		if other, ok := rules[k]; ok {
+			return fmt.Errorf("rule %s is duplicated between %s and %s", k, other.QualifiedName(), rule.QualifiedName())
+		}
+		rules[k] = rule
+		return nil
+	})
+	return rules, err
+}
+
+// diffKey returns the name of the rule in AWS qualified with the account, the region and the event bus.
+func diffKey(rule *Rule) string {
+	return qualifiedName(rule.Account, rule.Region, rule.EventBusName, deployedName(rule))
+}
+
+// deployedName returns DeployedName of the rule if set, otherwise Name.
+func deployedName(rule *Rule) string {
+	if rule.DeployedName != "" {
+		return rule.DeployedName
+	}
+	return rule.Name
+}
+
+func describeSchedule(rule *Rule) string {
+	if rule.TimeZone == nil || rule.TimeZone.String() == time.UTC.String() {
+		return rule.ScheduleExpression
+	}
+	return rule.ScheduleExpression + " " + rule.TimeZone.String()
+}
+
+// DriftFormats is the names of the formats available in WriteDrifts.
+var DriftFormats = []string{"tsv", "jsonl", "json"}
+
+// WriteDrifts writes the drifts in the format, `kind<TAB>name<TAB>declared<TAB>live` lines for tsv.
+func WriteDrifts(w io.Writer, format string, drifts []*Drift) error {
+	switch strings.ToLower(format) {
+	case "", "tsv":
+		for _, d := range drifts {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Kind, d.Name, d.Declared, d.Live); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, d := range drifts {
+			if err := enc.Encode(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		if drifts == nil {
+			drifts = []*Drift{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(drifts)
+	default:
+		return fmt.Errorf("unknown format %q, available formats are %s", format, strings.Join(DriftFormats, ", "))
+	}
+}
+
+// DriftError is returned by RunDiffWithContext when the declared rules and the live rules differ.
+type DriftError struct {
+	Drifts []*Drift
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("%d drifts between the declared rules and the live rules", len(e.Drifts))
+}
@@ -0,0 +1,186 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+const testDeclaredTemplate = `Resources:
+  DailyRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Name: daily
+      ScheduleExpression: cron(0 18 * * ? *)
+  HourlyRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Name: hourly
+      ScheduleExpression: rate(1 hour)
+  RemovedRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Name: removed
+      ScheduleExpression: rate(1 day)
+  UnnamedRule:
+    Type: AWS::Events::Rule
+    Properties:
+      ScheduleExpression: rate(1 day)
+  Schedule:
+    Type: AWS::Scheduler::Schedule
+    Properties:
+      Name: schedule
+      GroupName: batch
+      State: DISABLED
+      ScheduleExpression: cron(0 9 * * ? *)
+      ScheduleExpressionTimezone: Asia/Tokyo
+`
+
+const testLiveRulesJSON = `{"Rules": [
+  {"Name": "daily", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"},
+  {"Name": "hourly", "State": "DISABLED", "ScheduleExpression": "rate(2 hours)"},
+  {"Name": "console", "State": "ENABLED", "ScheduleExpression": "rate(5 minutes)"}
+]}
+{"Name": "schedule", "GroupName": "batch", "State": "DISABLED", "ScheduleExpression": "cron(0 9 * * ? *)", "ScheduleExpressionTimezone": "UTC"}
+`
+
+func TestDiff(t *testing.T) {
+	drifts, err := rules2cron.Diff(context.Background(),
+		rules2cron.NewCloudFormationSource(strings.NewReader(testDeclaredTemplate), nil),
+		rules2cron.NewJSONSource(strings.NewReader(testLiveRulesJSON)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*rules2cron.Drift{
+		{Name: "batch/schedule", Kind: rules2cron.DriftExpressionChanged, Declared: "cron(0 9 * * ? *) Asia/Tokyo", Live: "cron(0 9 * * ? *)"},
+		{Name: "console", Kind: rules2cron.DriftAdded, Live: "rate(5 minutes)"},
+		{Name: "hourly", Kind: rules2cron.DriftExpressionChanged, Declared: "rate(1 hour)", Live: "rate(2 hours)"},
+		{Name: "hourly", Kind: rules2cron.DriftStateChanged, Declared: "ENABLED", Live: "DISABLED"},
+		{Name: "removed", Kind: rules2cron.DriftRemoved, Declared: "rate(1 day)"},
+	}, drifts)
+}
+
+func TestWriteDrifts(t *testing.T) {
+	drifts := []*rules2cron.Drift{
+		{Name: "console", Kind: rules2cron.DriftAdded, Live: "rate(5 minutes)"},
+		{Name: "hourly", Kind: rules2cron.DriftStateChanged, Declared: "ENABLED", Live: "DISABLED"},
+	}
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "tsv",
+			expected: "added\tconsole\t\trate(5 minutes)\nstate_changed\thourly\tENABLED\tDISABLED\n",
+		},
+		{
+			format: "jsonl",
+			expected: `{"name":"console","kind":"added","live":"rate(5 minutes)"}` + "\n" +
+				`{"name":"hourly","kind":"state_changed","declared":"ENABLED","live":"DISABLED"}` + "\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, rules2cron.WriteDrifts(&buf, c.format, drifts))
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+	require.EqualError(t, rules2cron.WriteDrifts(&bytes.Buffer{}, "xml", drifts), `unknown format "xml", available formats are tsv, jsonl, json`)
+}
+
+func TestDiffEventBuses(t *testing.T) {
+	drifts, err := rules2cron.Diff(context.Background(),
+		rules2cron.NewCloudFormationSource(strings.NewReader(`Resources:
+  DefaultRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Name: daily
+      ScheduleExpression: cron(0 18 * * ? *)
+  CustomRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Name: daily
+      EventBusName: custom
+      ScheduleExpression: cron(0 9 * * ? *)
+`), nil),
+		rules2cron.NewJSONSource(strings.NewReader(`{"Rules": [
+  {"Name": "daily", "EventBusName": "default", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"},
+  {"Name": "daily", "EventBusName": "custom", "State": "ENABLED", "ScheduleExpression": "cron(0 10 * * ? *)"},
+  {"Name": "daily", "EventBusName": "partner", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"}
+]}`)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*rules2cron.Drift{
+		{Name: "custom/daily", Kind: rules2cron.DriftExpressionChanged, Declared: "cron(0 9 * * ? *)", Live: "cron(0 10 * * ? *)"},
+		{Name: "partner/daily", Kind: rules2cron.DriftAdded, Live: "cron(0 18 * * ? *)"},
+	}, drifts)
+}
+
+func TestDiffRegions(t *testing.T) {
+	live := []*rules2cron.Rule{
+		{Name: "daily", EventBusName: "default", State: "ENABLED", ScheduleExpression: "cron(0 18 * * ? *)", Account: "123456789012", Region: "ap-northeast-1"},
+		{Name: "daily", EventBusName: "default", State: "ENABLED", ScheduleExpression: "cron(0 9 * * ? *)", Account: "123456789012", Region: "us-west-2"},
+	}
+	declared := []*rules2cron.Rule{
+		{Name: "daily", EventBusName: "default", State: "ENABLED", ScheduleExpression: "cron(0 18 * * ? *)", Account: "123456789012", Region: "ap-northeast-1"},
+	}
+	drifts, err := rules2cron.Diff(context.Background(), testRulesSource(declared), testRulesSource(live))
+	require.NoError(t, err)
+	require.Equal(t, []*rules2cron.Drift{
+		{Name: "123456789012/us-west-2/daily", Kind: rules2cron.DriftAdded, Live: "cron(0 9 * * ? *)"},
+	}, drifts)
+
+	declared[0].Account, declared[0].Region = "", ""
+	_, err = rules2cron.Diff(context.Background(), testRulesSource(declared), testRulesSource(live))
+	require.EqualError(t, err, "rule daily is duplicated between 123456789012/ap-northeast-1/daily and 123456789012/us-west-2/daily")
+
+	drifts, err = rules2cron.Diff(context.Background(), testRulesSource(declared), testRulesSource(live[:1]))
+	require.NoError(t, err)
+	require.Empty(t, drifts)
+}
+
+type testRulesSource []*rules2cron.Rule
+
+func (s testRulesSource) Rules(_ context.Context, fn func(*rules2cron.Rule) error) error {
+	for _, rule := range s {
+		if err := fn(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAppRunDiff(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testLiveRulesJSON))
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	err = app.RunDiffWithContext(context.Background(), &buf, rules2cron.DiffOptions{
+		Declared: rules2cron.NewCloudFormationSource(strings.NewReader(testDeclaredTemplate), nil),
+		Format:   "json",
+	})
+	var driftErr *rules2cron.DriftError
+	require.True(t, errors.As(err, &driftErr))
+	require.Len(t, driftErr.Drifts, 5)
+	require.Contains(t, buf.String(), `"kind": "removed"`)
+}
+
+func TestAppRunDiffWithState(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testLiveRulesJSON))
+		o.Filter.States = []string{"ENABLED"}
+	})
+	require.NoError(t, err)
+	err = app.RunDiffWithContext(context.Background(), io.Discard, rules2cron.DiffOptions{
+		Declared: rules2cron.NewCloudFormationSource(strings.NewReader(testDeclaredTemplate), nil),
+		Format:   "json",
+	})
+	require.EqualError(t, err, "tag and state filters are not available in diff")
+}
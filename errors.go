@@ -0,0 +1,103 @@
+package rules2cron
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is the machine-readable code of the conversion error.
+type ErrorCode string
+
+const (
+	// ErrorCodeParse is the code of *ParseError.
+	ErrorCodeParse ErrorCode = "parse_error"
+	// ErrorCodeUnsupported is the code of *UnsupportedError.
+	ErrorCodeUnsupported ErrorCode = "unsupported_syntax"
+	// ErrorCodeOutOfTarget is the code of *OutOfTargetError.
+	ErrorCodeOutOfTarget ErrorCode = "out_of_target"
+	// ErrorCodeLossy is the code of *LossyConversionError.
+	ErrorCodeLossy ErrorCode = "lossy_conversion"
+	// ErrorCodeUnknown is the code of the other errors.
+	ErrorCodeUnknown ErrorCode = "unknown"
+)
+
+// CodeOf returns the error code of err.
+func CodeOf(err error) ErrorCode {
+	var coder interface {
+		Code() ErrorCode
+	}
+	if errors.As(err, &coder) {
+		return coder.Code()
+	}
+	return ErrorCodeUnknown
+}
+
+// Code returns ErrorCodeParse.
+func (e *ParseError) Code() ErrorCode {
+	return ErrorCodeParse
+}
+
+// UnsupportedError is the error that the expression is valid, but the syntax can not be converted into crontab.
+type UnsupportedError struct {
+	Expression string
+	Message    string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Message
+}
+
+// Code returns ErrorCodeUnsupported.
+func (e *UnsupportedError) Code() ErrorCode {
+	return ErrorCodeUnsupported
+}
+
+// OutOfTargetError is the error that the expression does not fire in the target year, month or period of the conversion.
+type OutOfTargetError struct {
+	Expression string
+	Message    string
+}
+
+func (e *OutOfTargetError) Error() string {
+	return e.Message
+}
+
+// Code returns ErrorCodeOutOfTarget.
+func (e *OutOfTargetError) Code() ErrorCode {
+	return ErrorCodeOutOfTarget
+}
+
+// LossyConversionError is the error that the crontab can not represent the expression exactly.
+// It is returned only when Converter.Strict is true.
+type LossyConversionError struct {
+	Expression string
+	Message    string
+}
+
+func (e *LossyConversionError) Error() string {
+	return "lossy conversion: " + e.Message
+}
+
+// Code returns ErrorCodeLossy.
+func (e *LossyConversionError) Code() ErrorCode {
+	return ErrorCodeLossy
+}
+
+func newUnsupportedError(expr Expression, format string, args ...interface{}) error {
+	return &UnsupportedError{Expression: expressionString(expr), Message: fmt.Sprintf(format, args...)}
+}
+
+func newOutOfTargetError(expr Expression, format string, args ...interface{}) error {
+	return &OutOfTargetError{Expression: expressionString(expr), Message: fmt.Sprintf(format, args...)}
+}
+
+func newLossyConversionError(expr Expression, format string, args ...interface{}) *LossyConversionError {
+	return &LossyConversionError{Expression: expressionString(expr), Message: fmt.Sprintf(format, args...)}
+}
+
+func expressionString(expr Expression) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
@@ -0,0 +1,66 @@
+package rules2cron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverterErrorCode(t *testing.T) {
+	cases := []struct {
+		scheduleExpression string
+		strict             bool
+		expectedCode       rules2cron.ErrorCode
+	}{
+		{scheduleExpression: "rate(1 days)", expectedCode: rules2cron.ErrorCodeParse},
+		{scheduleExpression: "cron(0 10 * *)", expectedCode: rules2cron.ErrorCodeParse},
+		{scheduleExpression: "cron(15 * * * ? 2023)", expectedCode: rules2cron.ErrorCodeOutOfTarget},
+		{scheduleExpression: "cron(15 * ? * 3#5 *)", expectedCode: rules2cron.ErrorCodeOutOfTarget},
+		{scheduleExpression: "at(2022-07-01T00:00:00)", expectedCode: rules2cron.ErrorCodeOutOfTarget},
+		{scheduleExpression: "cron(15 * ? * 3#1,4 *)", expectedCode: rules2cron.ErrorCodeUnsupported},
+		{scheduleExpression: "rate(7 minutes)", strict: true, expectedCode: rules2cron.ErrorCodeLossy},
+		{scheduleExpression: "rate(2 days)", strict: true, expectedCode: rules2cron.ErrorCodeLossy},
+		{scheduleExpression: "cron(15 * L * ? *)", strict: true, expectedCode: rules2cron.ErrorCodeLossy},
+		{scheduleExpression: "cron(15 * * * ? 2022)", strict: true, expectedCode: rules2cron.ErrorCodeLossy},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      time.UTC,
+				Strict:        c.strict,
+			}
+			_, err := converter.Convert(c.scheduleExpression)
+			require.Error(t, err)
+			require.Equal(t, c.expectedCode, rules2cron.CodeOf(err))
+		})
+	}
+}
+
+func TestConverterErrorsAs(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+		Strict:        true,
+	}
+	_, err := converter.Convert("cron(0 0 ? * MON-FRX *)")
+	var parseErr *rules2cron.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, 4, parseErr.Field)
+
+	_, err = converter.Convert("cron(15 * * * ? 2023)")
+	var outOfTargetErr *rules2cron.OutOfTargetError
+	require.True(t, errors.As(err, &outOfTargetErr))
+	require.Equal(t, "cron(15 * * * ? 2023)", outOfTargetErr.Expression)
+
+	_, err = converter.Convert("rate(5 hours)")
+	var lossyErr *rules2cron.LossyConversionError
+	require.True(t, errors.As(err, &lossyErr))
+	require.EqualError(t, err, "lossy conversion: rate(5 hours) restarts every day in crontab")
+
+	_, err = converter.Convert("rate(6 hours)")
+	require.NoError(t, err)
+}
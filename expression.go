@@ -0,0 +1,597 @@
+package rules2cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a parsed ScheduleExpression: *RateExpression, *CronExpression or *AtExpression.
+type Expression interface {
+	String() string
+	expression()
+}
+
+// RateUnit is the unit of rate() expression.
+type RateUnit string
+
+const (
+	RateUnitMinutes RateUnit = "minutes"
+	RateUnitHours   RateUnit = "hours"
+	RateUnitDays    RateUnit = "days"
+)
+
+// Duration returns the length of the unit.
+func (u RateUnit) Duration() time.Duration {
+	switch u {
+	case RateUnitMinutes:
+		return time.Minute
+	case RateUnitHours:
+		return time.Hour
+	case RateUnitDays:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// RateExpression is rate(Value Unit)
+type RateExpression struct {
+	Value uint64
+	Unit  RateUnit
+}
+
+func (*RateExpression) expression() {}
+
+func (e *RateExpression) String() string {
+	unit := string(e.Unit)
+	if e.Value == 1 {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	return fmt.Sprintf("rate(%d %s)", e.Value, unit)
+}
+
+// Interval returns the duration between executions.
+func (e *RateExpression) Interval() time.Duration {
+	return time.Duration(e.Value) * e.Unit.Duration()
+}
+
+// AtExpression is at(yyyy-mm-ddThh:mm:ss) of EventBridge Scheduler's one-time schedule.
+type AtExpression struct {
+	Year   int
+	Month  time.Month
+	Day    int
+	Hour   int
+	Minute int
+	Second int
+}
+
+func (*AtExpression) expression() {}
+
+func (e *AtExpression) String() string {
+	return fmt.Sprintf("at(%s)", e.Time(time.UTC).Format(atLayout))
+}
+
+// Time returns the instant of the expression evaluated in the location.
+func (e *AtExpression) Time(loc *time.Location) time.Time {
+	return time.Date(e.Year, e.Month, e.Day, e.Hour, e.Minute, e.Second, 0, loc)
+}
+
+const atLayout = "2006-01-02T15:04:05"
+
+// CronExpression is cron(Minutes Hours Day-of-month Month Day-of-week Year)
+type CronExpression struct {
+	Minutes    *CronField
+	Hours      *CronField
+	DayOfMonth *CronField
+	Month      *CronField
+	DayOfWeek  *CronField
+	Year       *CronField
+}
+
+func (*CronExpression) expression() {}
+
+func (e *CronExpression) String() string {
+	parts := make([]string, 0, 6)
+	for _, f := range e.Fields() {
+		parts = append(parts, f.String())
+	}
+	return "cron(" + strings.Join(parts, " ") + ")"
+}
+
+// Fields returns the fields in the order of the expression.
+func (e *CronExpression) Fields() []*CronField {
+	return []*CronField{e.Minutes, e.Hours, e.DayOfMonth, e.Month, e.DayOfWeek, e.Year}
+}
+
+// CronFieldKind is the position of the field in cron() expression.
+type CronFieldKind int
+
+const (
+	CronFieldMinutes CronFieldKind = iota
+	CronFieldHours
+	CronFieldDayOfMonth
+	CronFieldMonth
+	CronFieldDayOfWeek
+	CronFieldYear
+)
+
+var cronFieldNames = []string{"minutes", "hours", "day-of-month", "month", "day-of-week", "year"}
+
+func (k CronFieldKind) String() string {
+	if int(k) < len(cronFieldNames) {
+		return cronFieldNames[k]
+	}
+	return fmt.Sprintf("CronFieldKind(%d)", int(k))
+}
+
+// Min returns the minimum value of the field.
+func (k CronFieldKind) Min() int {
+	switch k {
+	case CronFieldDayOfMonth, CronFieldMonth, CronFieldDayOfWeek:
+		return 1
+	case CronFieldYear:
+		return 1970
+	default:
+		return 0
+	}
+}
+
+// Max returns the maximum value of the field.
+func (k CronFieldKind) Max() int {
+	switch k {
+	case CronFieldMinutes:
+		return 59
+	case CronFieldHours:
+		return 23
+	case CronFieldDayOfMonth:
+		return 31
+	case CronFieldMonth:
+		return 12
+	case CronFieldDayOfWeek:
+		return 7
+	case CronFieldYear:
+		return 2199
+	default:
+		return 0
+	}
+}
+
+// CronField is a field of cron() expression, comma separated items.
+type CronField struct {
+	Kind  CronFieldKind
+	Pos   int
+	Text  string
+	Items []*CronItem
+}
+
+func (f *CronField) String() string {
+	return f.Text
+}
+
+// IsAny reports whether the field is `*` or `?`.
+func (f *CronField) IsAny() bool {
+	for _, item := range f.Items {
+		if item.Kind == CronItemAll && item.Step <= 1 {
+			return true
+		}
+		if item.Kind == CronItemNoSpecific {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNoSpecific reports whether the field is `?`.
+func (f *CronField) IsNoSpecific() bool {
+	return len(f.Items) == 1 && f.Items[0].Kind == CronItemNoSpecific
+}
+
+// HasSpecialItem reports whether the field has L, W or # items.
+func (f *CronField) HasSpecialItem() bool {
+	for _, item := range f.Items {
+		if item.IsSpecial() {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the value matches with any items of the field, ignoring L, W and # items.
+func (f *CronField) Match(value int) bool {
+	for _, item := range f.Items {
+		if item.Match(f.Kind, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// CronItemKind is the kind of a comma separated item in the field.
+type CronItemKind int
+
+const (
+	// CronItemAll is `*` or `*/Step`
+	CronItemAll CronItemKind = iota
+	// CronItemNoSpecific is `?`
+	CronItemNoSpecific
+	// CronItemValue is `Start` or `Start/Step`
+	CronItemValue
+	// CronItemRange is `Start-End` or `Start-End/Step`.
+	// Start may be greater than End except in the year, a range wrapping around the end of the field such as 22-2 or FRI-MON.
+	CronItemRange
+	// CronItemLast is `L` in the day-of-month, `L` or `StartL` in the day-of-week.
+	CronItemLast
+	// CronItemWeekday is `StartW` in the day-of-month.
+	CronItemWeekday
+	// CronItemLastWeekday is `LW` in the day-of-month.
+	CronItemLastWeekday
+	// CronItemNth is `Start#Nth` in the day-of-week.
+	CronItemNth
+)
+
+// CronItem is a comma separated item of the field.
+// Values of the day-of-week are 1 (SUN) to 7 (SAT), the same as EventBridge.
+type CronItem struct {
+	Kind  CronItemKind
+	Pos   int
+	Text  string
+	Start int
+	End   int
+	Step  int
+	Nth   int
+}
+
+func (item *CronItem) String() string {
+	return item.Text
+}
+
+// IsSpecial reports whether the item is L, W or #, which depends on the month.
+func (item *CronItem) IsSpecial() bool {
+	switch item.Kind {
+	case CronItemLast, CronItemWeekday, CronItemLastWeekday, CronItemNth:
+		return true
+	default:
+		return false
+	}
+}
+
+// Bounds returns the first and the last value of the item in the field.
+func (item *CronItem) Bounds(kind CronFieldKind) (int, int) {
+	switch item.Kind {
+	case CronItemAll, CronItemNoSpecific:
+		return kind.Min(), kind.Max()
+	case CronItemValue:
+		if item.Step > 0 {
+			return item.Start, kind.Max()
+		}
+		return item.Start, item.Start
+	case CronItemRange:
+		return item.Start, item.End
+	default:
+		return item.Start, item.Start
+	}
+}
+
+// Match reports whether the value matches with the item. L, W and # never match.
+func (item *CronItem) Match(kind CronFieldKind, value int) bool {
+	if item.IsSpecial() {
+		return false
+	}
+	start, end := item.Bounds(kind)
+	offset := value - start
+	if start > end {
+		// wrapping range such as FRI-MON
+		if value < start && value > end {
+			return false
+		}
+		if value < start {
+			offset += kind.Max() - kind.Min() + 1
+		}
+	} else if value < start || value > end {
+		return false
+	}
+	if item.Step <= 1 {
+		return true
+	}
+	return offset%item.Step == 0
+}
+
+// ParseError is the error of ParseExpression.
+type ParseError struct {
+	Expression string
+	// Field is the index of the field in cron() expression, -1 if the error is not in the field.
+	Field int
+	// Pos is the byte offset of the error in the expression.
+	Pos     int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	if e.Message == "" {
+		return "invalid format"
+	}
+	return "invalid format: " + e.Message
+}
+
+// ParseExpression parses ScheduleExpression of EventBridge.
+func ParseExpression(scheduleExpression string) (Expression, error) {
+	switch {
+	case strings.HasPrefix(scheduleExpression, "rate("):
+		return parseRateExpression(scheduleExpression)
+	case strings.HasPrefix(scheduleExpression, "cron("):
+		return ParseCronExpression(scheduleExpression)
+	case strings.HasPrefix(scheduleExpression, "at("):
+		return parseAtExpression(scheduleExpression)
+	default:
+		return nil, &ParseError{Expression: scheduleExpression, Field: -1}
+	}
+}
+
+func parseRateExpression(scheduleExpression string) (*RateExpression, error) {
+	newError := func(format string, args ...interface{}) error {
+		return &ParseError{
+			Expression: scheduleExpression,
+			Field:      -1,
+			Pos:        len("rate("),
+			Message:    fmt.Sprintf(format, args...),
+		}
+	}
+	if !strings.HasSuffix(scheduleExpression, ")") {
+		return nil, newError("require rate(Value Unit) ")
+	}
+	parts := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(scheduleExpression, "rate("), ")"))
+	if len(parts) != 2 {
+		return nil, newError("require rate(Value Unit) ")
+	}
+	value, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, newError("parse Value: %s", err)
+	}
+	if value == 0 {
+		return nil, newError("require Value over 0")
+	}
+	unit := parts[1]
+	var plural bool
+	switch unit {
+	case "minute", "hour", "day":
+	case "minutes", "hours", "days":
+		plural = true
+	default:
+		return nil, newError("unknown unit: %s", unit)
+	}
+	if value == 1 && plural {
+		return nil, newError("can not use pluralistic")
+	}
+	if value != 1 && !plural {
+		return nil, newError("can not use singular form")
+	}
+	return &RateExpression{
+		Value: value,
+		Unit:  RateUnit(strings.TrimSuffix(unit, "s") + "s"),
+	}, nil
+}
+
+func parseAtExpression(scheduleExpression string) (*AtExpression, error) {
+	value := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(scheduleExpression, "at("), ")"))
+	t, err := time.Parse(atLayout, value)
+	if err != nil || !strings.HasSuffix(scheduleExpression, ")") {
+		return nil, &ParseError{
+			Expression: scheduleExpression,
+			Field:      -1,
+			Pos:        len("at("),
+			Message:    "require at(yyyy-mm-ddThh:mm:ss)",
+		}
+	}
+	return &AtExpression{
+		Year:   t.Year(),
+		Month:  t.Month(),
+		Day:    t.Day(),
+		Hour:   t.Hour(),
+		Minute: t.Minute(),
+		Second: t.Second(),
+	}, nil
+}
+
+// ParseCronExpression parses cron(Minutes Hours Day-of-month Month Day-of-week Year)
+func ParseCronExpression(scheduleExpression string) (*CronExpression, error) {
+	if !strings.HasPrefix(scheduleExpression, "cron(") || !strings.HasSuffix(scheduleExpression, ")") {
+		return nil, &ParseError{Expression: scheduleExpression, Field: -1}
+	}
+	body := scheduleExpression[len("cron(") : len(scheduleExpression)-1]
+	fields := make([]*CronField, 0, 6)
+	offset := len("cron(")
+	for i := 0; i < len(body); {
+		if body[i] == ' ' || body[i] == '\t' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(body) && body[j] != ' ' && body[j] != '\t' {
+			j++
+		}
+		fields = append(fields, &CronField{
+			Kind: CronFieldKind(len(fields)),
+			Pos:  offset + i,
+			Text: body[i:j],
+		})
+		i = j
+	}
+	if len(fields) != 6 {
+		return nil, &ParseError{
+			Expression: scheduleExpression,
+			Field:      -1,
+			Pos:        offset,
+			Message:    "require cron(Minutes Hours Day-of-month Month Day-of-week Year) ",
+		}
+	}
+	for _, f := range fields {
+		if err := parseCronField(f); err != nil {
+			err.Expression = scheduleExpression
+			return nil, err
+		}
+	}
+	if dayOfMonth, dayOfWeek := fields[2], fields[4]; !dayOfMonth.IsNoSpecific() && !dayOfWeek.IsNoSpecific() {
+		return nil, &ParseError{
+			Expression: scheduleExpression,
+			Field:      int(dayOfWeek.Kind),
+			Pos:        dayOfWeek.Pos,
+			Message:    dayOfWeek.Kind.String() + ": require ? in either the day-of-month or the day-of-week",
+		}
+	}
+	return &CronExpression{
+		Minutes:    fields[0],
+		Hours:      fields[1],
+		DayOfMonth: fields[2],
+		Month:      fields[3],
+		DayOfWeek:  fields[4],
+		Year:       fields[5],
+	}, nil
+}
+
+func parseCronField(f *CronField) *ParseError {
+	pos := f.Pos
+	for _, text := range strings.Split(f.Text, ",") {
+		item, err := parseCronItem(f.Kind, text, pos)
+		if err != nil {
+			return err
+		}
+		f.Items = append(f.Items, item)
+		pos += len(text) + 1
+	}
+	return nil
+}
+
+var (
+	monthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+func parseCronItem(kind CronFieldKind, text string, pos int) (*CronItem, *ParseError) {
+	newError := func(offset int, format string, args ...interface{}) *ParseError {
+		return &ParseError{
+			Field:   int(kind),
+			Pos:     pos + offset,
+			Message: kind.String() + ": " + fmt.Sprintf(format, args...),
+		}
+	}
+	item := &CronItem{Pos: pos, Text: text}
+	upper := strings.ToUpper(text)
+	if upper == "" {
+		return nil, newError(0, "empty value")
+	}
+	body := upper
+	if i := strings.IndexByte(body, '/'); i >= 0 {
+		step, err := strconv.Atoi(body[i+1:])
+		if err != nil || step <= 0 {
+			return nil, newError(i+1, "invalid step %q", text[i+1:])
+		}
+		item.Step = step
+		body = body[:i]
+	}
+	parseValue := func(s string, offset int) (int, *ParseError) {
+		var names []string
+		switch kind {
+		case CronFieldMonth:
+			names = monthNames
+		case CronFieldDayOfWeek:
+			names = weekdayNames
+		}
+		for i, name := range names {
+			if s == name {
+				return i + 1, nil
+			}
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, newError(offset, "invalid value %q", s)
+		}
+		if v < kind.Min() || v > kind.Max() {
+			return 0, newError(offset, "value %d out of range %d-%d", v, kind.Min(), kind.Max())
+		}
+		return v, nil
+	}
+	noStep := func() *ParseError {
+		if item.Step > 0 {
+			return newError(len(body), "step is not allowed with %q", body)
+		}
+		return nil
+	}
+	switch {
+	case body == "*":
+		item.Kind = CronItemAll
+		return item, nil
+	case body == "?":
+		if kind != CronFieldDayOfMonth && kind != CronFieldDayOfWeek {
+			return nil, newError(0, "? is allowed only in the day-of-month and the day-of-week")
+		}
+		item.Kind = CronItemNoSpecific
+		return item, noStep()
+	case kind == CronFieldDayOfMonth && body == "L":
+		item.Kind = CronItemLast
+		return item, noStep()
+	case kind == CronFieldDayOfMonth && body == "LW":
+		item.Kind = CronItemLastWeekday
+		return item, noStep()
+	case kind == CronFieldDayOfMonth && strings.HasSuffix(body, "W"):
+		v, err := parseValue(strings.TrimSuffix(body, "W"), 0)
+		if err != nil {
+			return nil, err
+		}
+		item.Kind = CronItemWeekday
+		item.Start = v
+		return item, noStep()
+	case kind == CronFieldDayOfWeek && body == "L":
+		item.Kind = CronItemLast
+		item.Start = 7
+		return item, noStep()
+	case kind == CronFieldDayOfWeek && strings.HasSuffix(body, "L"):
+		v, err := parseValue(strings.TrimSuffix(body, "L"), 0)
+		if err != nil {
+			return nil, err
+		}
+		item.Kind = CronItemLast
+		item.Start = v
+		return item, noStep()
+	case kind == CronFieldDayOfWeek && strings.ContainsRune(body, '#'):
+		i := strings.IndexByte(body, '#')
+		v, err := parseValue(body[:i], 0)
+		if err != nil {
+			return nil, err
+		}
+		nth, convErr := strconv.Atoi(body[i+1:])
+		if convErr != nil || nth < 1 || nth > 5 {
+			return nil, newError(i+1, "invalid nth %q", body[i+1:])
+		}
+		item.Kind = CronItemNth
+		item.Start = v
+		item.Nth = nth
+		return item, noStep()
+	case strings.ContainsRune(body, '-'):
+		i := strings.IndexByte(body, '-')
+		start, err := parseValue(body[:i], 0)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseValue(body[i+1:], i+1)
+		if err != nil {
+			return nil, err
+		}
+		if kind == CronFieldYear && start > end {
+			return nil, newError(0, "descending range %q", text)
+		}
+		item.Kind = CronItemRange
+		item.Start = start
+		item.End = end
+		return item, nil
+	default:
+		v, err := parseValue(body, 0)
+		if err != nil {
+			return nil, err
+		}
+		item.Kind = CronItemValue
+		item.Start = v
+		return item, nil
+	}
+}
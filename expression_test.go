@@ -0,0 +1,198 @@
+package rules2cron_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpression(t *testing.T) {
+	cases := []struct {
+		scheduleExpression string
+		expected           rules2cron.Expression
+	}{
+		{
+			scheduleExpression: "rate(5 minutes)",
+			expected:           &rules2cron.RateExpression{Value: 5, Unit: rules2cron.RateUnitMinutes},
+		},
+		{
+			scheduleExpression: "rate(1 day)",
+			expected:           &rules2cron.RateExpression{Value: 1, Unit: rules2cron.RateUnitDays},
+		},
+		{
+			scheduleExpression: "at(2022-06-15T10:30:00)",
+			expected:           &rules2cron.AtExpression{Year: 2022, Month: 6, Day: 15, Hour: 10, Minute: 30},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			actual, err := rules2cron.ParseExpression(c.scheduleExpression)
+			require.NoError(t, err)
+			require.EqualValues(t, c.expected, actual)
+			require.Equal(t, c.scheduleExpression, actual.String())
+		})
+	}
+}
+
+func TestParseCronExpression(t *testing.T) {
+	expr, err := rules2cron.ParseCronExpression("cron(0/10 10-12/2 ? JAN,mar-MAY 2#1 2022)")
+	require.NoError(t, err)
+	require.Equal(t, "cron(0/10 10-12/2 ? JAN,mar-MAY 2#1 2022)", expr.String())
+	require.EqualValues(t, []*rules2cron.CronItem{
+		{Kind: rules2cron.CronItemValue, Pos: 5, Text: "0/10", Start: 0, Step: 10},
+	}, expr.Minutes.Items)
+	require.EqualValues(t, []*rules2cron.CronItem{
+		{Kind: rules2cron.CronItemRange, Pos: 10, Text: "10-12/2", Start: 10, End: 12, Step: 2},
+	}, expr.Hours.Items)
+	require.True(t, expr.DayOfMonth.IsNoSpecific())
+	require.EqualValues(t, []*rules2cron.CronItem{
+		{Kind: rules2cron.CronItemValue, Pos: 20, Text: "JAN", Start: 1},
+		{Kind: rules2cron.CronItemRange, Pos: 24, Text: "mar-MAY", Start: 3, End: 5},
+	}, expr.Month.Items)
+	require.EqualValues(t, []*rules2cron.CronItem{
+		{Kind: rules2cron.CronItemNth, Pos: 32, Text: "2#1", Start: 2, Nth: 1},
+	}, expr.DayOfWeek.Items)
+	require.True(t, expr.Year.Match(2022))
+	require.False(t, expr.Year.Match(2023))
+}
+
+func TestParseCronExpressionSpecialItems(t *testing.T) {
+	expr, err := rules2cron.ParseCronExpression("cron(0 0 L,LW,15W * ? *)")
+	require.NoError(t, err)
+	kinds := make([]rules2cron.CronItemKind, 0)
+	for _, item := range expr.DayOfMonth.Items {
+		kinds = append(kinds, item.Kind)
+	}
+	expr, err = rules2cron.ParseCronExpression("cron(0 0 ? * 6L *)")
+	require.NoError(t, err)
+	for _, item := range expr.DayOfWeek.Items {
+		kinds = append(kinds, item.Kind)
+	}
+	require.EqualValues(t, []rules2cron.CronItemKind{
+		rules2cron.CronItemLast,
+		rules2cron.CronItemLastWeekday,
+		rules2cron.CronItemWeekday,
+		rules2cron.CronItemLast,
+	}, kinds)
+	require.Equal(t, 6, expr.DayOfWeek.Items[0].Start)
+}
+
+func TestParseCronExpressionWrappingRange(t *testing.T) {
+	expr, err := rules2cron.ParseCronExpression("cron(50-10 22-2/2 30-2 NOV-FEB ? *)")
+	require.NoError(t, err)
+	require.EqualValues(t, []*rules2cron.CronItem{
+		{Kind: rules2cron.CronItemRange, Pos: 11, Text: "22-2/2", Start: 22, End: 2, Step: 2},
+	}, expr.Hours.Items)
+	cases := []struct {
+		field    *rules2cron.CronField
+		matched  []int
+		excluded []int
+	}{
+		{field: expr.Minutes, matched: []int{50, 59, 0, 10}, excluded: []int{11, 49}},
+		{field: expr.Hours, matched: []int{22, 0, 2}, excluded: []int{23, 1, 3, 21}},
+		{field: expr.DayOfMonth, matched: []int{30, 31, 1, 2}, excluded: []int{3, 29}},
+		{field: expr.Month, matched: []int{11, 12, 1, 2}, excluded: []int{3, 10}},
+	}
+	for _, c := range cases {
+		for _, v := range c.matched {
+			require.True(t, c.field.Match(v), "%s should match %d", c.field, v)
+		}
+		for _, v := range c.excluded {
+			require.False(t, c.field.Match(v), "%s should not match %d", c.field, v)
+		}
+	}
+}
+
+func TestParseExpressionError(t *testing.T) {
+	cases := []struct {
+		scheduleExpression string
+		expectedField      int
+		expectedPos        int
+		expectedError      string
+	}{
+		{
+			scheduleExpression: "every(5 minutes)",
+			expectedField:      -1,
+			expectedError:      "invalid format",
+		},
+		{
+			scheduleExpression: "rate(2 minute)",
+			expectedField:      -1,
+			expectedPos:        5,
+			expectedError:      "invalid format: can not use singular form",
+		},
+		{
+			scheduleExpression: "cron(0 10 * *)",
+			expectedField:      -1,
+			expectedPos:        5,
+			expectedError:      "invalid format: require cron(Minutes Hours Day-of-month Month Day-of-week Year) ",
+		},
+		{
+			scheduleExpression: "cron(0 10,24 * * ? *)",
+			expectedField:      1,
+			expectedPos:        10,
+			expectedError:      "invalid format: hours: value 24 out of range 0-23",
+		},
+		{
+			scheduleExpression: "cron(0 10 ? * MON-FRX *)",
+			expectedField:      4,
+			expectedPos:        18,
+			expectedError:      `invalid format: day-of-week: invalid value "FRX"`,
+		},
+		{
+			scheduleExpression: "cron(0 10 ? * 2#6 *)",
+			expectedField:      4,
+			expectedPos:        16,
+			expectedError:      `invalid format: day-of-week: invalid nth "6"`,
+		},
+		{
+			scheduleExpression: "cron(0/x 10 * * ? *)",
+			expectedField:      0,
+			expectedPos:        7,
+			expectedError:      `invalid format: minutes: invalid step "x"`,
+		},
+		{
+			scheduleExpression: "cron(0 10 * * ? 2023-2022)",
+			expectedField:      5,
+			expectedPos:        16,
+			expectedError:      `invalid format: year: descending range "2023-2022"`,
+		},
+		{
+			scheduleExpression: "cron(0 18 15 * MON *)",
+			expectedField:      4,
+			expectedPos:        15,
+			expectedError:      "invalid format: day-of-week: require ? in either the day-of-month or the day-of-week",
+		},
+		{
+			scheduleExpression: "cron(0 10 * * * *)",
+			expectedField:      4,
+			expectedPos:        14,
+			expectedError:      "invalid format: day-of-week: require ? in either the day-of-month or the day-of-week",
+		},
+		{
+			scheduleExpression: "cron(? 10 * * ? *)",
+			expectedField:      0,
+			expectedPos:        5,
+			expectedError:      "invalid format: minutes: ? is allowed only in the day-of-month and the day-of-week",
+		},
+		{
+			scheduleExpression: "cron(0 0,? * * ? *)",
+			expectedField:      1,
+			expectedPos:        9,
+			expectedError:      "invalid format: hours: ? is allowed only in the day-of-month and the day-of-week",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			_, err := rules2cron.ParseExpression(c.scheduleExpression)
+			require.EqualError(t, err, c.expectedError)
+			var parseErr *rules2cron.ParseError
+			require.True(t, errors.As(err, &parseErr))
+			require.Equal(t, c.scheduleExpression, parseErr.Expression)
+			require.Equal(t, c.expectedField, parseErr.Field)
+			require.Equal(t, c.expectedPos, parseErr.Pos)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package rules2cron
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// RuleStates is the states of the rules available in RuleFilter.States.
+var RuleStates = []string{"ENABLED", "DISABLED", "ENABLED_WITH_ALL_CLOUDTRAIL_MANAGEMENT_EVENTS"}
+
+// RuleFilter selects the rules, the zero value selects all the rules.
+type RuleFilter struct {
+	// NamePrefix selects the rules whose name starts with it, passed to ListRules and ListSchedules.
+	// The name of the schedule is without the group name.
+	NamePrefix string
+	// Include selects the rules whose name matches any of them, all the rules if empty.
+	Include []*regexp.Regexp
+	// Exclude drops the rules whose name matches any of them.
+	Exclude []*regexp.Regexp
+	// Tags selects the rules which have all of the tags, resolved with ListTagsForResource.
+	// It is available only with AWSSource.
+	Tags map[string]string
+	// States selects the rules in any of the states, such as ENABLED, DISABLED and ENABLED_WITH_ALL_CLOUDTRAIL_MANAGEMENT_EVENTS.
+	// All the states if empty.
+	States []string
+}
+
+// Match reports whether the rule is selected by the name and the state, Tags is not evaluated.
+func (f *RuleFilter) Match(rule *Rule) bool {
+	if f.NamePrefix != "" && !strings.HasPrefix(baseName(rule), f.NamePrefix) {
+		return false
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, rule.Name) {
+		return false
+	}
+	if matchAny(f.Exclude, rule.Name) {
+		return false
+	}
+	if len(f.States) > 0 {
+		for _, state := range f.States {
+			if strings.EqualFold(state, rule.State) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// baseName returns the name of the rule in AWS, without the group name of the schedule.
+func baseName(rule *Rule) string {
+	name := deployedName(rule)
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTags reports whether the tags have all of the selected tags.
+func matchTags(selected, tags map[string]string) bool {
+	for key, value := range selected {
+		if v, ok := tags[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// filteredSource is the source which passes the rules selected by the filter.
+type filteredSource struct {
+	src    Source
+	filter *RuleFilter
+}
+
+func (src *filteredSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	return src.src.Rules(ctx, func(rule *Rule) error {
+		if !src.filter.Match(rule) {
+			return nil
+		}
+		return fn(rule)
+	})
+}
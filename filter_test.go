@@ -0,0 +1,87 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleFilter(t *testing.T) {
+	rules := []*rules2cron.Rule{
+		{Name: "team-a-daily", State: "ENABLED"},
+		{Name: "team-a-hourly", State: "DISABLED"},
+		{Name: "team-b-daily", State: "ENABLED_WITH_ALL_CLOUDTRAIL_MANAGEMENT_EVENTS"},
+		{Name: "batch/team-a-weekly", State: "ENABLED"},
+		{Name: "DailyRule", DeployedName: "team-b-nightly", State: "ENABLED"},
+	}
+	cases := []struct {
+		name     string
+		filter   rules2cron.RuleFilter
+		expected []string
+	}{
+		{
+			name:     "zero",
+			expected: []string{"team-a-daily", "team-a-hourly", "team-b-daily", "batch/team-a-weekly", "DailyRule"},
+		},
+		{
+			name:     "name prefix",
+			filter:   rules2cron.RuleFilter{NamePrefix: "team-a"},
+			expected: []string{"team-a-daily", "team-a-hourly", "batch/team-a-weekly"},
+		},
+		{
+			name:     "deployed name prefix",
+			filter:   rules2cron.RuleFilter{NamePrefix: "team-b"},
+			expected: []string{"team-b-daily", "DailyRule"},
+		},
+		{
+			name: "include and exclude",
+			filter: rules2cron.RuleFilter{
+				Include: []*regexp.Regexp{regexp.MustCompile(`daily$`), regexp.MustCompile(`weekly$`)},
+				Exclude: []*regexp.Regexp{regexp.MustCompile(`^team-b`)},
+			},
+			expected: []string{"team-a-daily", "batch/team-a-weekly"},
+		},
+		{
+			name:     "states",
+			filter:   rules2cron.RuleFilter{States: []string{"DISABLED", "enabled_with_all_cloudtrail_management_events"}},
+			expected: []string{"team-a-hourly", "team-b-daily"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := make([]string, 0)
+			for _, rule := range rules {
+				if c.filter.Match(rule) {
+					actual = append(actual, rule.Name)
+				}
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestAppWithFilter(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+		o.Filter = rules2cron.RuleFilter{States: []string{"DISABLED"}}
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Equal(t, "*/5 * * * *\tdisabled\n", buf.String())
+
+	_, err = rules2cron.New(context.Background(), &rules2cron.Converter{}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+		o.Filter = rules2cron.RuleFilter{Tags: map[string]string{"team": "batch"}}
+	})
+	require.EqualError(t, err, "tag filters are available only with the rules of AWS")
+}
@@ -0,0 +1,161 @@
+package rules2cron
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Record is a crontab line converted from a rule, the unit of the output.
+type Record struct {
+	Name                       string     `json:"name" yaml:"name"`
+	Arn                        string     `json:"arn" yaml:"arn"`
+	EventBusName               string     `json:"event_bus_name" yaml:"event_bus_name"`
+	State                      string     `json:"state" yaml:"state"`
+	ScheduleExpression         string     `json:"schedule_expression" yaml:"schedule_expression"`
+	ScheduleExpressionTimezone string     `json:"schedule_expression_timezone" yaml:"schedule_expression_timezone"`
+	Cron                       string     `json:"cron" yaml:"cron"`
+	ValidFrom                  *time.Time `json:"valid_from,omitempty" yaml:"valid_from,omitempty"`
+	ValidTo                    *time.Time `json:"valid_to,omitempty" yaml:"valid_to,omitempty"`
+	Warning                    string     `json:"warning,omitempty" yaml:"warning,omitempty"`
+	Account                    string     `json:"account,omitempty" yaml:"account,omitempty"`
+	Region                     string     `json:"region,omitempty" yaml:"region,omitempty"`
+	Targets                    []*Target  `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// Formatter writes the records.
+type Formatter interface {
+	Format(w io.Writer, records []*Record) error
+}
+
+// FormatterFunc is an adapter to use a function as Formatter.
+type FormatterFunc func(w io.Writer, records []*Record) error
+
+// Format calls f(w, records)
+func (f FormatterFunc) Format(w io.Writer, records []*Record) error {
+	return f(w, records)
+}
+
+// Formats is the names of the formatters available in NewFormatter.
+var Formats = []string{"tsv", "jsonl", "json", "yaml", "csv"}
+
+// NewFormatter returns the formatter of the name.
+func NewFormatter(format string) (Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", "tsv":
+		return FormatterFunc(formatTSV), nil
+	case "jsonl":
+		return FormatterFunc(formatJSONLines), nil
+	case "json":
+		return FormatterFunc(formatJSON), nil
+	case "yaml":
+		return FormatterFunc(formatYAML), nil
+	case "csv":
+		return FormatterFunc(formatCSV), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, available formats are %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// formatTSV writes `cron<TAB>name` lines, the input of cronv.
+// The name is prefixed with the event bus name unless it is the default event bus,
+// and with the account and the region if tagged, such as `123456789012/ap-northeast-1/custom/name`.
+func formatTSV(w io.Writer, records []*Record) error {
+	for _, r := range records {
+		name := qualifiedName(r.Account, r.Region, r.EventBusName, r.Name)
+		if r.ValidFrom == nil || r.ValidTo == nil {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", r.Cron, name); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s (%s ~ %s)\n", r.Cron, name, r.ValidFrom.Format(periodLayout), r.ValidTo.Format(periodLayout)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const periodLayout = "2006-01-02T15:04"
+
+func formatJSONLines(w io.Writer, records []*Record) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatJSON(w io.Writer, records []*Record) error {
+	if records == nil {
+		records = []*Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+func formatYAML(w io.Writer, records []*Record) error {
+	if records == nil {
+		records = []*Record{}
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(records); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+var csvHeader = []string{"name", "arn", "event_bus_name", "state", "schedule_expression", "schedule_expression_timezone", "cron", "valid_from", "valid_to", "warning", "account", "region", "targets"}
+
+// targetArns returns the ARNs of the targets separated by the space.
+func targetArns(targets []*Target) string {
+	arns := make([]string, 0, len(targets))
+	for _, t := range targets {
+		arns = append(arns, t.Arn)
+	}
+	return strings.Join(arns, " ")
+}
+
+func formatCSV(w io.Writer, records []*Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, r := range records {
+		err := cw.Write([]string{
+			r.Name,
+			r.Arn,
+			r.EventBusName,
+			r.State,
+			r.ScheduleExpression,
+			r.ScheduleExpressionTimezone,
+			r.Cron,
+			formatTime(r.ValidFrom),
+			formatTime(r.ValidTo),
+			r.Warning,
+			r.Account,
+			r.Region,
+			targetArns(r.Targets),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
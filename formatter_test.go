@@ -0,0 +1,102 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatter(t *testing.T) {
+	from := time.Date(2022, 3, 13, 3, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 11, 6, 1, 0, 0, 0, time.UTC)
+	records := []*rules2cron.Record{
+		{
+			Name:                       "daily",
+			Arn:                        "arn:aws:events:ap-northeast-1:123456789012:rule/daily",
+			EventBusName:               "default",
+			State:                      "ENABLED",
+			ScheduleExpression:         "rate(7 minutes)",
+			ScheduleExpressionTimezone: "UTC",
+			Cron:                       "*/7 * * * *",
+			Warning:                    "lossy conversion: rate(7 minutes) restarts every hour in crontab",
+		},
+		{
+			Name:                       "split",
+			Arn:                        "arn:aws:events:ap-northeast-1:123456789012:rule/split",
+			EventBusName:               "default",
+			State:                      "DISABLED",
+			ScheduleExpression:         "cron(0 10 * * ? *)",
+			ScheduleExpressionTimezone: "UTC",
+			Cron:                       "0 3 * * *",
+			ValidFrom:                  &from,
+			ValidTo:                    &to,
+		},
+	}
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "tsv",
+			expected: "*/7 * * * *\tdaily\n" +
+				"0 3 * * *\tsplit (2022-03-13T03:00 ~ 2022-11-06T01:00)\n",
+		},
+		{
+			format: "jsonl",
+			expected: `{"name":"daily","arn":"arn:aws:events:ap-northeast-1:123456789012:rule/daily","event_bus_name":"default","state":"ENABLED","schedule_expression":"rate(7 minutes)","schedule_expression_timezone":"UTC","cron":"*/7 * * * *","warning":"lossy conversion: rate(7 minutes) restarts every hour in crontab"}` + "\n" +
+				`{"name":"split","arn":"arn:aws:events:ap-northeast-1:123456789012:rule/split","event_bus_name":"default","state":"DISABLED","schedule_expression":"cron(0 10 * * ? *)","schedule_expression_timezone":"UTC","cron":"0 3 * * *","valid_from":"2022-03-13T03:00:00Z","valid_to":"2022-11-06T01:00:00Z"}` + "\n",
+		},
+		{
+			format: "csv",
+			expected: "name,arn,event_bus_name,state,schedule_expression,schedule_expression_timezone,cron,valid_from,valid_to,warning,account,region,targets\n" +
+				"daily,arn:aws:events:ap-northeast-1:123456789012:rule/daily,default,ENABLED,rate(7 minutes),UTC,*/7 * * * *,,,lossy conversion: rate(7 minutes) restarts every hour in crontab,,,\n" +
+				"split,arn:aws:events:ap-northeast-1:123456789012:rule/split,default,DISABLED,cron(0 10 * * ? *),UTC,0 3 * * *,2022-03-13T03:00:00Z,2022-11-06T01:00:00Z,,,,\n",
+		},
+		{
+			format: "yaml",
+			expected: `- name: daily
+  arn: arn:aws:events:ap-northeast-1:123456789012:rule/daily
+  event_bus_name: default
+  state: ENABLED
+  schedule_expression: rate(7 minutes)
+  schedule_expression_timezone: UTC
+  cron: '*/7 * * * *'
+  warning: 'lossy conversion: rate(7 minutes) restarts every hour in crontab'
+- name: split
+  arn: arn:aws:events:ap-northeast-1:123456789012:rule/split
+  event_bus_name: default
+  state: DISABLED
+  schedule_expression: cron(0 10 * * ? *)
+  schedule_expression_timezone: UTC
+  cron: 0 3 * * *
+  valid_from: 2022-03-13T03:00:00Z
+  valid_to: 2022-11-06T01:00:00Z
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			formatter, err := rules2cron.NewFormatter(c.format)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, formatter.Format(&buf, records))
+			require.Equal(t, c.expected, buf.String())
+		})
+	}
+}
+
+func TestFormatterJSONEmpty(t *testing.T) {
+	formatter, err := rules2cron.NewFormatter("json")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, formatter.Format(&buf, nil))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestNewFormatterUnknown(t *testing.T) {
+	_, err := rules2cron.NewFormatter("xml")
+	require.EqualError(t, err, `unknown format "xml", available formats are tsv, jsonl, json, yaml, csv`)
+}
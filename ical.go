@@ -0,0 +1,251 @@
+package rules2cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICalendarOptions is the options for NewICalendarEvents.
+type ICalendarOptions struct {
+	// From and To is the period to expand the fire times, [From, To).
+	From time.Time
+	To   time.Time
+	// Duration is the duration of the events, 15 minutes if zero.
+	Duration time.Duration
+}
+
+// ICalendarEvent is a VEVENT of iCalendar (RFC 5545).
+// RRule is the recurrence rule without UNTIL, empty if the event is a single instance.
+type ICalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	Until       time.Time
+	Duration    time.Duration
+	RRule       string
+}
+
+const defaultICalendarEventDuration = 15 * time.Minute
+
+// NewICalendarEvents expands the fire times of the rule in the period into the events, evaluated with the EventBridge semantics.
+// The rule is a recurring event with RRULE if iCalendar can represent it in UTC, otherwise an event for each fire time.
+func NewICalendarEvents(rule *Rule, opts ICalendarOptions) ([]*ICalendarEvent, error) {
+	expr, err := ParseExpression(rule.ScheduleExpression)
+	if err != nil {
+		return nil, err
+	}
+	loc := rule.TimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+	duration := opts.Duration
+	if duration <= 0 {
+		duration = defaultICalendarEventDuration
+	}
+	times := FireTimesBetween(expr, opts.From, opts.To, loc)
+	if len(times) == 0 {
+		return nil, nil
+	}
+	base := &ICalendarEvent{
+		UID:         rule.QualifiedName() + "@rules2cron",
+		Summary:     rule.Name,
+		Description: icalDescription(rule),
+		Duration:    duration,
+	}
+	if rrule, ok := icalRRule(expr, loc); ok {
+		event := *base
+		event.Start = times[0]
+		if len(times) > 1 {
+			event.RRule = rrule
+			event.Until = times[len(times)-1]
+		}
+		return []*ICalendarEvent{&event}, nil
+	}
+	events := make([]*ICalendarEvent, 0, len(times))
+	for _, t := range times {
+		event := *base
+		event.Start = t
+		if len(times) > 1 {
+			event.UID = fmt.Sprintf("%s-%s@rules2cron", rule.QualifiedName(), t.UTC().Format(icalTimeLayout))
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+func icalDescription(rule *Rule) string {
+	lines := make([]string, 0, 3)
+	if rule.Arn != "" {
+		lines = append(lines, rule.Arn)
+	}
+	expr := rule.ScheduleExpression
+	if rule.TimeZone != nil && rule.TimeZone.String() != "UTC" {
+		expr += " " + rule.TimeZone.String()
+	}
+	lines = append(lines, expr)
+	if rule.State != "" {
+		lines = append(lines, rule.State)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var icalWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// icalRRule returns the recurrence rule of the expression without UNTIL, if iCalendar can represent it exactly.
+// The cron() expressions are represented only in UTC, since the time zone definitions are not written.
+func icalRRule(expr Expression, loc *time.Location) (string, bool) {
+	switch e := expr.(type) {
+	case *RateExpression:
+		freq := map[RateUnit]string{
+			RateUnitMinutes: "MINUTELY",
+			RateUnitHours:   "HOURLY",
+			RateUnitDays:    "DAILY",
+		}[e.Unit]
+		if freq == "" {
+			return "", false
+		}
+		if e.Value == 1 {
+			return "FREQ=" + freq, true
+		}
+		return fmt.Sprintf("FREQ=%s;INTERVAL=%d", freq, e.Value), true
+	case *CronExpression:
+		if loc.String() != "UTC" || !e.Year.IsAny() {
+			return "", false
+		}
+		return icalCronRRule(e)
+	default:
+		return "", false
+	}
+}
+
+func icalCronRRule(e *CronExpression) (string, bool) {
+	freq := "DAILY"
+	parts := make([]string, 0, 6)
+	if !e.Month.IsAny() {
+		parts = append(parts, "BYMONTH="+icalValues(e.Month))
+	}
+	if !e.DayOfMonth.IsAny() {
+		values := make([]string, 0)
+		for _, item := range e.DayOfMonth.Items {
+			switch item.Kind {
+			case CronItemLast:
+				values = append(values, "-1")
+			case CronItemWeekday, CronItemLastWeekday:
+				return "", false
+			}
+		}
+		if plain := icalValues(e.DayOfMonth); plain != "" {
+			values = append([]string{plain}, values...)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(values, ","))
+	}
+	if !e.DayOfWeek.IsAny() {
+		values := make([]string, 0)
+		for v := 1; v <= 7; v++ {
+			if e.DayOfWeek.Match(v) {
+				values = append(values, icalWeekdays[v-1])
+			}
+		}
+		for _, item := range e.DayOfWeek.Items {
+			switch item.Kind {
+			case CronItemLast:
+				values = append(values, "-1"+icalWeekdays[item.Start-1])
+			case CronItemNth:
+				values = append(values, strconv.Itoa(item.Nth)+icalWeekdays[item.Start-1])
+			}
+		}
+		if e.DayOfWeek.HasSpecialItem() {
+			// the numeric BYDAY is allowed only in MONTHLY or YEARLY
+			freq = "MONTHLY"
+		}
+		parts = append(parts, "BYDAY="+strings.Join(values, ","))
+	}
+	parts = append(parts, "BYHOUR="+icalValues(e.Hours), "BYMINUTE="+icalValues(e.Minutes))
+	return "FREQ=" + freq + ";" + strings.Join(parts, ";"), true
+}
+
+// icalValues returns the comma separated values matched with the plain items of the field.
+func icalValues(f *CronField) string {
+	values := make([]string, 0)
+	for v := f.Kind.Min(); v <= f.Kind.Max(); v++ {
+		if f.Match(v) {
+			values = append(values, strconv.Itoa(v))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+const icalTimeLayout = "20060102T150405Z"
+
+// WriteICalendar writes the events as a VCALENDAR, stamp is the DTSTAMP of the events.
+func WriteICalendar(w io.Writer, events []*ICalendarEvent, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	writeLine := func(name, value string) {
+		line := name + ":" + value
+		// fold the line longer than 75 octets, the continuation lines start with a space
+		limit := 75
+		for len(line) > limit {
+			cut := limit
+			for cut > 1 && !isUTF8Start(line[cut]) {
+				cut--
+			}
+			bw.WriteString(line[:cut] + "\r\n ")
+			line = line[cut:]
+			limit = 74
+		}
+		bw.WriteString(line + "\r\n")
+	}
+	writeLine("BEGIN", "VCALENDAR")
+	writeLine("VERSION", "2.0")
+	writeLine("PRODID", "-//mashiike//rules2cron//EN")
+	writeLine("CALSCALE", "GREGORIAN")
+	for _, event := range events {
+		writeLine("BEGIN", "VEVENT")
+		writeLine("UID", icalEscape(event.UID))
+		writeLine("DTSTAMP", stamp.UTC().Format(icalTimeLayout))
+		writeLine("DTSTART", event.Start.UTC().Format(icalTimeLayout))
+		writeLine("DURATION", icalDuration(event.Duration))
+		if event.RRule != "" {
+			writeLine("RRULE", event.RRule+";UNTIL="+event.Until.UTC().Format(icalTimeLayout))
+		}
+		writeLine("SUMMARY", icalEscape(event.Summary))
+		if event.Description != "" {
+			writeLine("DESCRIPTION", icalEscape(event.Description))
+		}
+		writeLine("END", "VEVENT")
+	}
+	writeLine("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func icalDuration(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	ret := "PT"
+	if h := d / time.Hour; h > 0 {
+		ret += fmt.Sprintf("%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		ret += fmt.Sprintf("%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 || ret == "PT" {
+		ret += fmt.Sprintf("%dS", d/time.Second)
+	}
+	return ret
+}
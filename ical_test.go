@@ -0,0 +1,154 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewICalendarEvents(t *testing.T) {
+	cases := []struct {
+		scheduleExpression         string
+		scheduleExpressionTimezone string
+		expectedRRule              string
+		expectedStarts             []time.Time
+	}{
+		{
+			scheduleExpression: "rate(30 minutes)",
+			expectedRRule:      "FREQ=MINUTELY;INTERVAL=30",
+			expectedStarts:     []time.Time{time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			scheduleExpression: "cron(0/30 9-10 ? * MON-FRI *)",
+			expectedRRule:      "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,10;BYMINUTE=0,30",
+			expectedStarts:     []time.Time{time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			scheduleExpression: "cron(0 3 L 6,7 ? *)",
+			expectedRRule:      "FREQ=DAILY;BYMONTH=6,7;BYMONTHDAY=-1;BYHOUR=3;BYMINUTE=0",
+			expectedStarts:     []time.Time{time.Date(2022, 6, 30, 3, 0, 0, 0, time.UTC)},
+		},
+		{
+			scheduleExpression: "cron(0 3 ? * 6#1,2L *)",
+			expectedRRule:      "FREQ=MONTHLY;BYDAY=1FR,-1MO;BYHOUR=3;BYMINUTE=0",
+			expectedStarts:     []time.Time{time.Date(2022, 6, 3, 3, 0, 0, 0, time.UTC)},
+		},
+		{
+			scheduleExpression: "cron(0 3 15W * ? *)",
+			expectedStarts: []time.Time{
+				time.Date(2022, 6, 15, 3, 0, 0, 0, time.UTC),
+				time.Date(2022, 7, 15, 3, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			scheduleExpression:         "cron(0 9 1 * ? *)",
+			scheduleExpressionTimezone: "America/New_York",
+			expectedStarts: []time.Time{
+				time.Date(2022, 6, 1, 13, 0, 0, 0, time.UTC),
+				time.Date(2022, 7, 1, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			scheduleExpression: "at(2022-06-10T12:00:00)",
+			expectedStarts:     []time.Time{time.Date(2022, 6, 10, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			scheduleExpression: "cron(0 3 * * ? 2023)",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			loc := time.UTC
+			if c.scheduleExpressionTimezone != "" {
+				loc = Must(time.LoadLocation(c.scheduleExpressionTimezone))
+			}
+			events, err := rules2cron.NewICalendarEvents(&rules2cron.Rule{
+				Name:               "test",
+				ScheduleExpression: c.scheduleExpression,
+				TimeZone:           loc,
+			}, rules2cron.ICalendarOptions{
+				From: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+			})
+			require.NoError(t, err)
+			require.Len(t, events, len(c.expectedStarts))
+			for i, event := range events {
+				require.Equal(t, c.expectedRRule, event.RRule)
+				require.True(t, c.expectedStarts[i].Equal(event.Start), "expected %s, got %s", c.expectedStarts[i], event.Start)
+			}
+		})
+	}
+}
+
+func TestWriteICalendar(t *testing.T) {
+	events, err := rules2cron.NewICalendarEvents(&rules2cron.Rule{
+		Name:               "daily, report",
+		Arn:                "arn:aws:events:ap-northeast-1:123456789012:rule/daily-report-for-the-accounting-department",
+		State:              "ENABLED",
+		ScheduleExpression: "cron(0 3 * * ? *)",
+		TimeZone:           time.UTC,
+	}, rules2cron.ICalendarOptions{
+		From:     time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+		Duration: 90 * time.Minute,
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rules2cron.WriteICalendar(&buf, events, time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)))
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//mashiike//rules2cron//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		`UID:daily\, report@rules2cron`,
+		"DTSTAMP:20220501T000000Z",
+		"DTSTART:20220601T030000Z",
+		"DURATION:PT1H30M",
+		"RRULE:FREQ=DAILY;BYHOUR=3;BYMINUTE=0;UNTIL=20220607T030000Z",
+		`SUMMARY:daily\, report`,
+		`DESCRIPTION:arn:aws:events:ap-northeast-1:123456789012:rule/daily-report-fo`,
+		` r-the-accounting-department\ncron(0 3 * * ? *)\nENABLED`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	require.Equal(t, expected, buf.String())
+}
+
+func TestICalendarEventsUID(t *testing.T) {
+	opts := rules2cron.ICalendarOptions{
+		From: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		rule     *rules2cron.Rule
+		expected []string
+	}{
+		{
+			rule:     &rules2cron.Rule{Name: "daily", EventBusName: "default", ScheduleExpression: "rate(1 day)"},
+			expected: []string{"daily@rules2cron"},
+		},
+		{
+			rule:     &rules2cron.Rule{Name: "daily", EventBusName: "custom", Account: "123456789012", Region: "us-west-2", ScheduleExpression: "rate(1 day)"},
+			expected: []string{"123456789012/us-west-2/custom/daily@rules2cron"},
+		},
+		{
+			rule:     &rules2cron.Rule{Name: "daily", EventBusName: "custom", ScheduleExpression: "cron(0 3 * * ? *)", TimeZone: Must(time.LoadLocation("Asia/Tokyo"))},
+			expected: []string{"custom/daily-20220601T180000Z@rules2cron", "custom/daily-20220602T180000Z@rules2cron"},
+		},
+	}
+	for _, c := range cases {
+		events, err := rules2cron.NewICalendarEvents(c.rule, opts)
+		require.NoError(t, err)
+		uids := make([]string, 0, len(events))
+		for _, event := range events {
+			uids = append(uids, event.UID)
+		}
+		require.Equal(t, c.expected, uids)
+	}
+}
@@ -0,0 +1,101 @@
+package eventbridgex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+/*
+   implemented the eventbridgex.ListEventBuses one by referring to the "github.com/aws/aws-sdk-go-v2/service/quicksight".ListAnalyses paginator.
+
+   The original, original code is here; https://github.com/aws/aws-sdk-go-v2/blob/service/quicksight/v1.18.0/service/quicksight/api_op_ListAnalyses.go#L158
+   The license for the original code is here.; https://github.com/aws/aws-sdk-go-v2/blob/service/quicksight/v1.18.0/LICENSE.txt
+*/
+
+// ListEventBusesAPIClient is a client that implements the ListEventBuses operation.
+type ListEventBusesAPIClient interface {
+	ListEventBuses(context.Context, *eventbridge.ListEventBusesInput, ...func(*eventbridge.Options)) (*eventbridge.ListEventBusesOutput, error)
+}
+
+// ListEventBusesPaginatorOptions is the paginator options for ListEventBuses
+type ListEventBusesPaginatorOptions struct {
+	// The maximum number of results to return.
+	Limit int32
+
+	// Set to true if pagination should stop if the service returns a pagination token
+	// that matches the most recent token provided to the service.
+	StopOnDuplicateToken bool
+}
+
+// ListEventBusesPaginator is a paginator for ListEventBuses
+type ListEventBusesPaginator struct {
+	options   ListEventBusesPaginatorOptions
+	client    ListEventBusesAPIClient
+	params    *eventbridge.ListEventBusesInput
+	nextToken *string
+	firstPage bool
+}
+
+// NewListEventBusesPaginator returns a new ListEventBusesPaginator
+func NewListEventBusesPaginator(client ListEventBusesAPIClient, params *eventbridge.ListEventBusesInput, optFns ...func(*ListEventBusesPaginatorOptions)) *ListEventBusesPaginator {
+	if params == nil {
+		params = &eventbridge.ListEventBusesInput{}
+	}
+
+	options := ListEventBusesPaginatorOptions{
+		Limit: 100,
+	}
+	if params.Limit != nil {
+		options.Limit = *params.Limit
+	}
+
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return &ListEventBusesPaginator{
+		options:   options,
+		client:    client,
+		params:    params,
+		firstPage: true,
+		nextToken: params.NextToken,
+	}
+}
+
+// HasMorePages returns a boolean indicating whether more pages are available
+func (p *ListEventBusesPaginator) HasMorePages() bool {
+	return p.firstPage || (p.nextToken != nil && len(*p.nextToken) != 0)
+}
+
+// NextPage retrieves the next ListEventBuses page.
+func (p *ListEventBusesPaginator) NextPage(ctx context.Context, optFns ...func(*eventbridge.Options)) (*eventbridge.ListEventBusesOutput, error) {
+	if !p.HasMorePages() {
+		return nil, fmt.Errorf("no more pages available")
+	}
+
+	params := *p.params
+	params.NextToken = p.nextToken
+
+	params.Limit = aws.Int32(p.options.Limit)
+
+	result, err := p.client.ListEventBuses(ctx, &params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	p.firstPage = false
+
+	prevToken := p.nextToken
+	p.nextToken = result.NextToken
+
+	if p.options.StopOnDuplicateToken &&
+		prevToken != nil &&
+		p.nextToken != nil &&
+		*prevToken == *p.nextToken {
+		p.nextToken = nil
+	}
+
+	return result, nil
+}
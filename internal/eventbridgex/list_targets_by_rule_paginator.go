@@ -0,0 +1,101 @@
+package eventbridgex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+/*
+   implemented the eventbridgex.ListTargetsByRule one by referring to the "github.com/aws/aws-sdk-go-v2/service/quicksight".ListAnalyses paginator.
+
+   The original, original code is here; https://github.com/aws/aws-sdk-go-v2/blob/service/quicksight/v1.18.0/service/quicksight/api_op_ListAnalyses.go#L158
+   The license for the original code is here.; https://github.com/aws/aws-sdk-go-v2/blob/service/quicksight/v1.18.0/LICENSE.txt
+*/
+
+// ListTargetsByRuleAPIClient is a client that implements the ListTargetsByRule operation.
+type ListTargetsByRuleAPIClient interface {
+	ListTargetsByRule(context.Context, *eventbridge.ListTargetsByRuleInput, ...func(*eventbridge.Options)) (*eventbridge.ListTargetsByRuleOutput, error)
+}
+
+// ListTargetsByRulePaginatorOptions is the paginator options for ListTargetsByRule
+type ListTargetsByRulePaginatorOptions struct {
+	// The maximum number of results to return.
+	Limit int32
+
+	// Set to true if pagination should stop if the service returns a pagination token
+	// that matches the most recent token provided to the service.
+	StopOnDuplicateToken bool
+}
+
+// ListTargetsByRulePaginator is a paginator for ListTargetsByRule
+type ListTargetsByRulePaginator struct {
+	options   ListTargetsByRulePaginatorOptions
+	client    ListTargetsByRuleAPIClient
+	params    *eventbridge.ListTargetsByRuleInput
+	nextToken *string
+	firstPage bool
+}
+
+// NewListTargetsByRulePaginator returns a new ListTargetsByRulePaginator
+func NewListTargetsByRulePaginator(client ListTargetsByRuleAPIClient, params *eventbridge.ListTargetsByRuleInput, optFns ...func(*ListTargetsByRulePaginatorOptions)) *ListTargetsByRulePaginator {
+	if params == nil {
+		params = &eventbridge.ListTargetsByRuleInput{}
+	}
+
+	options := ListTargetsByRulePaginatorOptions{
+		Limit: 100,
+	}
+	if params.Limit != nil {
+		options.Limit = *params.Limit
+	}
+
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return &ListTargetsByRulePaginator{
+		options:   options,
+		client:    client,
+		params:    params,
+		firstPage: true,
+		nextToken: params.NextToken,
+	}
+}
+
+// HasMorePages returns a boolean indicating whether more pages are available
+func (p *ListTargetsByRulePaginator) HasMorePages() bool {
+	return p.firstPage || (p.nextToken != nil && len(*p.nextToken) != 0)
+}
+
+// NextPage retrieves the next ListTargetsByRule page.
+func (p *ListTargetsByRulePaginator) NextPage(ctx context.Context, optFns ...func(*eventbridge.Options)) (*eventbridge.ListTargetsByRuleOutput, error) {
+	if !p.HasMorePages() {
+		return nil, fmt.Errorf("no more pages available")
+	}
+
+	params := *p.params
+	params.NextToken = p.nextToken
+
+	params.Limit = aws.Int32(p.options.Limit)
+
+	result, err := p.client.ListTargetsByRule(ctx, &params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	p.firstPage = false
+
+	prevToken := p.nextToken
+	p.nextToken = result.NextToken
+
+	if p.options.StopOnDuplicateToken &&
+		prevToken != nil &&
+		p.nextToken != nil &&
+		*prevToken == *p.nextToken {
+		p.nextToken = nil
+	}
+
+	return result, nil
+}
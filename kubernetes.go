@@ -0,0 +1,164 @@
+package rules2cron
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CronJob is a batch/v1 CronJob manifest of Kubernetes.
+type CronJob struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   CronJobMetadata `yaml:"metadata"`
+	Spec       CronJobSpec     `yaml:"spec"`
+}
+
+type CronJobMetadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type CronJobSpec struct {
+	Schedule string `yaml:"schedule"`
+	// TimeZone is the time zone of the rule, Kubernetes handles the daylight saving time.
+	TimeZone    string     `yaml:"timeZone,omitempty"`
+	Suspend     bool       `yaml:"suspend,omitempty"`
+	JobTemplate *yaml.Node `yaml:"jobTemplate"`
+}
+
+// CronJobOptions is the options for ConvertToCronJobs.
+type CronJobOptions struct {
+	Namespace string
+	// JobTemplate renders the jobTemplate of the CronJob in YAML, executed with *CronJobTemplateData.
+	// If nil, DefaultCronJobJobTemplate is used.
+	JobTemplate *template.Template
+}
+
+// CronJobTemplateData is the data passed to CronJobOptions.JobTemplate.
+type CronJobTemplateData struct {
+	// Name is the name of the CronJob.
+	Name     string
+	Rule     *Rule
+	Schedule *Schedule
+}
+
+// DefaultCronJobJobTemplate is the default jobTemplate, a placeholder container which echoes the rule name.
+var DefaultCronJobJobTemplate = template.Must(template.New("jobTemplate").Parse(`spec:
+  template:
+    spec:
+      restartPolicy: OnFailure
+      containers:
+        - name: job
+          image: busybox
+          args: ["echo", "{{ .Rule.Name }}"]
+`))
+
+// ConvertToCronJobs converts the rule into the CronJob manifests of Kubernetes.
+// The schedule is evaluated in the time zone of the rule with the timeZone field, so TimeZone of the converter is not applied.
+// When the rule needs multiple crontab lines, the CronJobs are named with the suffix -1, -2, ...
+func (c *Converter) ConvertToCronJobs(rule *Rule, opts CronJobOptions) ([]*CronJob, error) {
+	expr, err := ParseExpression(rule.ScheduleExpression)
+	if err != nil {
+		return nil, err
+	}
+	converter := *c
+	converter.TimeZone = rule.TimeZone
+	schedules, err := converter.ConvertToSchedules(expr, rule.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := opts.JobTemplate
+	if tmpl == nil {
+		tmpl = DefaultCronJobJobTemplate
+	}
+	name := kubernetesName(rule.QualifiedName())
+	cronJobs := make([]*CronJob, 0, len(schedules))
+	for i, s := range schedules {
+		cronJob := &CronJob{
+			APIVersion: "batch/v1",
+			Kind:       "CronJob",
+			Metadata: CronJobMetadata{
+				Name:      name,
+				Namespace: opts.Namespace,
+				Annotations: map[string]string{
+					"rules2cron/name":                rule.Name,
+					"rules2cron/schedule-expression": rule.ScheduleExpression,
+				},
+			},
+			Spec: CronJobSpec{
+				Schedule: s.String(),
+				TimeZone: rule.TimeZone.String(),
+				Suspend:  rule.State == "DISABLED",
+			},
+		}
+		if rule.Arn != "" {
+			cronJob.Metadata.Annotations["rules2cron/arn"] = rule.Arn
+		}
+		if len(schedules) > 1 {
+			cronJob.Metadata.Name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, &CronJobTemplateData{
+			Name:     cronJob.Metadata.Name,
+			Rule:     rule,
+			Schedule: s,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("job template: %w", err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			return nil, fmt.Errorf("job template: %w", err)
+		}
+		if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("job template: must be a mapping")
+		}
+		cronJob.Spec.JobTemplate = doc.Content[0]
+		cronJobs = append(cronJobs, cronJob)
+	}
+	return cronJobs, nil
+}
+
+// kubernetesNameMaxLength is the max length of the CronJob name, the name of the Job has 11 more characters.
+const kubernetesNameMaxLength = 52
+
+// kubernetesName returns the name of DNS-1123 subdomain, lowercase alphanumerics and `-`.
+// A long name is truncated with the hash of the name, so that the truncated names do not conflict.
+func kubernetesName(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			return r
+		case 'A' <= r && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	if len(name) > kubernetesNameMaxLength-3 {
+		// keep room for the suffix of the split schedules
+		name = fmt.Sprintf("%s-%08x", strings.TrimRight(name[:kubernetesNameMaxLength-12], "-"), h.Sum32())
+	}
+	return strings.Trim(name, "-")
+}
+
+// WriteCronJobs writes the CronJobs as a multi-document YAML stream.
+func WriteCronJobs(w io.Writer, cronJobs []*CronJob) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, cronJob := range cronJobs {
+		if err := enc.Encode(cronJob); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
@@ -0,0 +1,116 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToCronJobs(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}
+	rule := &rules2cron.Rule{
+		Name:               "Daily_Report",
+		Arn:                "arn:aws:scheduler:ap-northeast-1:123456789012:schedule/default/Daily_Report",
+		State:              "DISABLED",
+		ScheduleExpression: "cron(0 3 ? * MON-FRI *)",
+		TimeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+	}
+	cronJobs, err := converter.ConvertToCronJobs(rule, rules2cron.CronJobOptions{Namespace: "batch"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rules2cron.WriteCronJobs(&buf, cronJobs))
+	require.Equal(t, `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: daily-report
+  namespace: batch
+  annotations:
+    rules2cron/arn: arn:aws:scheduler:ap-northeast-1:123456789012:schedule/default/Daily_Report
+    rules2cron/name: Daily_Report
+    rules2cron/schedule-expression: cron(0 3 ? * MON-FRI *)
+spec:
+  schedule: 0 3 * * 1-5
+  timeZone: America/Los_Angeles
+  suspend: true
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          restartPolicy: OnFailure
+          containers:
+            - name: job
+              image: busybox
+              args: ["echo", "Daily_Report"]
+`, buf.String())
+}
+
+func TestConvertToCronJobsJobTemplate(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}
+	rule := &rules2cron.Rule{
+		Name:               "twice",
+		State:              "ENABLED",
+		ScheduleExpression: "cron(0 3 1,15 * ? *)",
+		TimeZone:           time.UTC,
+	}
+	tmpl := template.Must(template.New("jobTemplate").Parse(`spec:
+  template:
+    metadata:
+      labels:
+        job: {{ .Name }}
+`))
+	cronJobs, err := converter.ConvertToCronJobs(rule, rules2cron.CronJobOptions{JobTemplate: tmpl})
+	require.NoError(t, err)
+	require.Len(t, cronJobs, 1)
+	require.Equal(t, "0 3 1,15 * *", cronJobs[0].Spec.Schedule)
+	require.False(t, cronJobs[0].Spec.Suspend)
+	var buf bytes.Buffer
+	require.NoError(t, rules2cron.WriteCronJobs(&buf, cronJobs))
+	require.Contains(t, buf.String(), `  jobTemplate:
+    spec:
+      template:
+        metadata:
+          labels:
+            job: twice
+`)
+
+	_, err = converter.ConvertToCronJobs(rule, rules2cron.CronJobOptions{
+		JobTemplate: template.Must(template.New("jobTemplate").Parse(`- {{ .Name }}`)),
+	})
+	require.EqualError(t, err, "job template: must be a mapping")
+}
+
+func TestConvertToCronJobsName(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}
+	long := strings.Repeat("daily-report-", 5)
+	cases := []struct {
+		rule     *rules2cron.Rule
+		expected string
+	}{
+		{rule: &rules2cron.Rule{Name: "Daily", EventBusName: "default"}, expected: "daily"},
+		{rule: &rules2cron.Rule{Name: "Daily", EventBusName: "custom"}, expected: "custom-daily"},
+		{rule: &rules2cron.Rule{Name: "Daily", Account: "123456789012", Region: "us-west-2"}, expected: "123456789012-us-west-2-daily"},
+		{rule: &rules2cron.Rule{Name: long + "a"}, expected: "daily-report-daily-report-daily-report-d-6f013f69"},
+		{rule: &rules2cron.Rule{Name: long + "b"}, expected: "daily-report-daily-report-daily-report-d-6c013ab0"},
+	}
+	for _, c := range cases {
+		c.rule.ScheduleExpression = "cron(0 3 * * ? *)"
+		c.rule.TimeZone = time.UTC
+		cronJobs, err := converter.ConvertToCronJobs(c.rule, rules2cron.CronJobOptions{})
+		require.NoError(t, err)
+		require.Equal(t, c.expected, cronJobs[0].Metadata.Name)
+	}
+}
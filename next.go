@@ -0,0 +1,192 @@
+package rules2cron
+
+import (
+	"time"
+)
+
+// Next returns the first fire time after t, evaluated in the location.
+// EventBridge starts rate() from the time the rule is created, which is unknown here, so the fire times are relative to t.
+func (e *RateExpression) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	return t.In(loc).Truncate(time.Minute).Add(e.Interval()), true
+}
+
+// Next returns the fire time if it is after t, evaluated in the location.
+func (e *AtExpression) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	at := e.Time(loc)
+	if !at.After(t) {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// Next returns the first fire time after t, evaluated in the location.
+// Local times skipped by the daylight saving time transition never fire.
+func (e *CronExpression) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	startYear, startMonth, startDay := start.Date()
+	for year := startYear; year <= CronFieldYear.Max(); year++ {
+		if !e.Year.Match(year) {
+			continue
+		}
+		for month := time.January; month <= time.December; month++ {
+			if year == startYear && month < startMonth {
+				continue
+			}
+			if !e.Month.Match(int(month)) {
+				continue
+			}
+			for day := 1; day <= lastDayOfMonth(year, month); day++ {
+				if year == startYear && month == startMonth && day < startDay {
+					continue
+				}
+				if !e.MatchDay(year, month, day) {
+					continue
+				}
+				startDate := year == startYear && month == startMonth && day == startDay
+				for hour := 0; hour < 24; hour++ {
+					if startDate && hour < start.Hour() {
+						continue
+					}
+					if !e.Hours.Match(hour) {
+						continue
+					}
+					for minute := 0; minute < 60; minute++ {
+						if startDate && hour == start.Hour() && minute < start.Minute() {
+							continue
+						}
+						if !e.Minutes.Match(minute) {
+							continue
+						}
+						candidate := time.Date(year, month, day, hour, minute, 0, 0, loc)
+						if candidate.Hour() != hour || candidate.Minute() != minute {
+							// skipped by the daylight saving time transition
+							continue
+						}
+						if candidate.Before(start) {
+							continue
+						}
+						return candidate, true
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// MatchDay reports whether the date matches with the day-of-month and the day-of-week, including L, W and # items.
+func (e *CronExpression) MatchDay(year int, month time.Month, day int) bool {
+	switch {
+	case e.DayOfMonth.IsNoSpecific():
+		return matchDayOfWeek(e.DayOfWeek, year, month, day)
+	case e.DayOfWeek.IsNoSpecific(), e.DayOfWeek.IsAny():
+		return matchDayOfMonth(e.DayOfMonth, year, month, day)
+	case e.DayOfMonth.IsAny():
+		return matchDayOfWeek(e.DayOfWeek, year, month, day)
+	default:
+		return matchDayOfMonth(e.DayOfMonth, year, month, day) && matchDayOfWeek(e.DayOfWeek, year, month, day)
+	}
+}
+
+func matchDayOfMonth(f *CronField, year int, month time.Month, day int) bool {
+	last := lastDayOfMonth(year, month)
+	for _, item := range f.Items {
+		switch item.Kind {
+		case CronItemLast:
+			if day == last {
+				return true
+			}
+		case CronItemLastWeekday:
+			if day == nearestWeekday(year, month, last) {
+				return true
+			}
+		case CronItemWeekday:
+			if item.Start <= last && day == nearestWeekday(year, month, item.Start) {
+				return true
+			}
+		default:
+			if item.Match(f.Kind, day) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchDayOfWeek(f *CronField, year int, month time.Month, day int) bool {
+	weekday := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()) + 1
+	for _, item := range f.Items {
+		switch item.Kind {
+		case CronItemLast:
+			if weekday == item.Start && day+7 > lastDayOfMonth(year, month) {
+				return true
+			}
+		case CronItemNth:
+			if weekday == item.Start && (day-1)/7+1 == item.Nth {
+				return true
+			}
+		default:
+			if item.Match(f.Kind, weekday) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextFireTimes returns at most n fire times of the expression after t, evaluated in the location.
+func NextFireTimes(expr Expression, t time.Time, loc *time.Location, n int) []time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	ret := make([]time.Time, 0, n)
+	for len(ret) < n {
+		next, ok := nextFireTime(expr, t, loc)
+		if !ok {
+			break
+		}
+		ret = append(ret, next)
+		t = next
+	}
+	return ret
+}
+
+// FireTimesBetween returns the fire times of the expression in [from, to), evaluated in the location.
+func FireTimesBetween(expr Expression, from, to time.Time, loc *time.Location) []time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	ret := make([]time.Time, 0)
+	t := from.Add(-time.Nanosecond)
+	if _, ok := expr.(*RateExpression); ok && from.Before(to) {
+		// rate() starts at from.
+		ret = append(ret, from.In(loc).Truncate(time.Minute))
+		t = ret[0]
+	}
+	for {
+		next, ok := nextFireTime(expr, t, loc)
+		if !ok || !next.Before(to) {
+			break
+		}
+		ret = append(ret, next)
+		t = next
+	}
+	return ret
+}
+
+func nextFireTime(expr Expression, t time.Time, loc *time.Location) (time.Time, bool) {
+	switch e := expr.(type) {
+	case *RateExpression:
+		return e.Next(t, loc)
+	case *CronExpression:
+		return e.Next(t, loc)
+	case *AtExpression:
+		return e.Next(t, loc)
+	default:
+		return time.Time{}, false
+	}
+}
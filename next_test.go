@@ -0,0 +1,100 @@
+package rules2cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextFireTimes(t *testing.T) {
+	newYork := Must(time.LoadLocation("America/New_York"))
+	cases := []struct {
+		scheduleExpression string
+		start              time.Time
+		loc                *time.Location
+		expected           []string
+	}{
+		{
+			scheduleExpression: "cron(0 10 * * ? *)",
+			start:              time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-06-02T10:00:00Z", "2022-06-03T10:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0/15 * * * ? *)",
+			start:              time.Date(2022, 6, 1, 9, 50, 0, 0, time.UTC),
+			expected:           []string{"2022-06-01T10:00:00Z", "2022-06-01T10:15:00Z", "2022-06-01T10:30:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 0 L * ? *)",
+			start:              time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-01-31T00:00:00Z", "2022-02-28T00:00:00Z", "2022-03-31T00:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 0 ? * 6L *)",
+			start:              time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-06-24T00:00:00Z", "2022-07-29T00:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 0 ? * 2#1 *)",
+			start:              time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-06-06T00:00:00Z", "2022-07-04T00:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 0 15W * ? *)",
+			start:              time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-01-14T00:00:00Z", "2022-02-15T00:00:00Z", "2022-03-15T00:00:00Z", "2022-04-15T00:00:00Z", "2022-05-16T00:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 18 ? * MON-FRI *)",
+			start:              time.Date(2022, 6, 3, 19, 0, 0, 0, time.UTC),
+			expected:           []string{"2022-06-06T18:00:00Z", "2022-06-07T18:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(0 0 1 1 ? 2024-2025)",
+			start:              time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			expected:           []string{"2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		},
+		{
+			scheduleExpression: "cron(30 2 * * ? *)",
+			start:              time.Date(2022, 3, 12, 0, 0, 0, 0, newYork),
+			loc:                newYork,
+			expected:           []string{"2022-03-12T02:30:00-05:00", "2022-03-14T02:30:00-04:00"},
+		},
+		{
+			scheduleExpression: "rate(5 minutes)",
+			start:              time.Date(2022, 6, 1, 10, 0, 30, 0, time.UTC),
+			expected:           []string{"2022-06-01T10:05:00Z", "2022-06-01T10:10:00Z"},
+		},
+		{
+			scheduleExpression: "at(2022-06-15T10:00:00)",
+			start:              time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+			loc:                Must(time.LoadLocation("Asia/Tokyo")),
+			expected:           []string{"2022-06-15T10:00:00+09:00"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			expr, err := rules2cron.ParseExpression(c.scheduleExpression)
+			require.NoError(t, err)
+			actual := rules2cron.NextFireTimes(expr, c.start, c.loc, len(c.expected)+1)
+			formatted := make([]string, 0, len(actual))
+			for _, a := range actual {
+				formatted = append(formatted, a.Format(time.RFC3339))
+			}
+			if len(formatted) > len(c.expected) {
+				formatted = formatted[:len(c.expected)]
+			}
+			require.EqualValues(t, c.expected, formatted)
+		})
+	}
+}
+
+func TestFireTimesBetween(t *testing.T) {
+	expr := Must(rules2cron.ParseExpression("cron(0 */6 * * ? *)"))
+	actual := rules2cron.FireTimesBetween(expr, time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 2, 0, 0, 0, 0, time.UTC), time.UTC)
+	require.Len(t, actual, 4)
+	require.True(t, actual[0].Equal(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, actual[3].Equal(time.Date(2022, 6, 1, 18, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,179 @@
+package rules2cron
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodSchedule is a crontab schedule which is valid in the period [From, To).
+type PeriodSchedule struct {
+	*Schedule
+	From time.Time
+	To   time.Time
+}
+
+// ConvertPeriod converts a parsed schedule expression evaluated in the given location for the period [from, to).
+// When the difference of the UTC offsets changes in the period, such as the daylight saving time,
+// the schedules are split into the periods which have the same difference.
+// When the cron() expression has L, W or # items, a schedule pinned to the month is emitted for each month in the period,
+// and From and To of the schedule are the period of the same difference of the UTC offsets.
+// When the year of the cron() expression is restricted, the periods are also split at the beginning of each year,
+// and the schedules are emitted for the years which match.
+func (c *Converter) ConvertPeriod(expr Expression, base *time.Location, from, to time.Time) ([]*PeriodSchedule, error) {
+	if c.TimeZone == nil {
+		c.TimeZone = time.Local
+	}
+	if base == nil {
+		base = time.UTC
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period: %s is not before %s", from, to)
+	}
+	if e, ok := expr.(*AtExpression); ok {
+		return c.convertAtPeriod(e, base, from, to)
+	}
+	monthly, yearly := false, false
+	var year *CronField
+	if e, ok := expr.(*CronExpression); ok {
+		monthly = e.DayOfMonth.HasSpecialItem() || e.DayOfWeek.HasSpecialItem()
+		yearly = !e.Year.IsAny()
+		year = e.Year
+	}
+	var firstErr error
+	ret := make([]*PeriodSchedule, 0)
+	for _, p := range offsetPeriods(from, to, base, c.TimeZone) {
+		pieces := []*offsetPeriod{p}
+		switch {
+		case monthly:
+			pieces = monthlyPeriods(p, base)
+		case yearly:
+			pieces = yearlyPeriods(p, base)
+		}
+		for _, piece := range pieces {
+			sub := *c
+			sub.ReferenceDate = piece.from.In(base)
+			valid := p
+			if yearly {
+				if !year.Match(sub.ReferenceDate.Year()) {
+					continue
+				}
+				valid = piece
+			}
+			if monthly {
+				if len(FireTimesBetween(expr, piece.from, piece.to, base)) == 0 {
+					continue
+				}
+				sub.pinMonth = true
+			}
+			schedules, err := sub.ConvertToSchedules(expr, base)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			for _, s := range schedules {
+				ret = append(ret, &PeriodSchedule{
+					Schedule: s,
+					From:     valid.from,
+					To:       valid.to,
+				})
+			}
+		}
+	}
+	if len(ret) == 0 {
+		if firstErr == nil {
+			firstErr = newOutOfTargetError(expr, "cannot be converted because the schedule does not fire in the period: %s", expr)
+		}
+		return nil, firstErr
+	}
+	return ret, nil
+}
+
+func (c *Converter) convertAtPeriod(e *AtExpression, base *time.Location, from, to time.Time) ([]*PeriodSchedule, error) {
+	at := e.Time(base)
+	if at.Before(from) || !at.Before(to) {
+		return nil, newOutOfTargetError(e, "cannot be converted because the schedule is not in the period: %s", e)
+	}
+	sub := *c
+	sub.ReferenceDate = at.In(c.TimeZone)
+	schedules, err := sub.ConvertToSchedules(e, base)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*PeriodSchedule, 0, len(schedules))
+	for _, s := range schedules {
+		ret = append(ret, &PeriodSchedule{Schedule: s, From: from, To: to})
+	}
+	return ret, nil
+}
+
+// monthlyPeriods splits the period at the beginning of each month in the location.
+func monthlyPeriods(p *offsetPeriod, loc *time.Location) []*offsetPeriod {
+	periods := make([]*offsetPeriod, 0, 1)
+	current := p.from
+	for current.Before(p.to) {
+		t := current.In(loc)
+		next := time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if next.After(p.to) {
+			next = p.to
+		}
+		periods = append(periods, &offsetPeriod{from: current, to: next, offset: p.offset})
+		current = next
+	}
+	return periods
+}
+
+// yearlyPeriods splits the period at the beginning of each year in the location.
+func yearlyPeriods(p *offsetPeriod, loc *time.Location) []*offsetPeriod {
+	periods := make([]*offsetPeriod, 0, 1)
+	current := p.from
+	for current.Before(p.to) {
+		t := current.In(loc)
+		next := time.Date(t.Year()+1, 1, 1, 0, 0, 0, 0, loc)
+		if next.After(p.to) {
+			next = p.to
+		}
+		periods = append(periods, &offsetPeriod{from: current, to: next, offset: p.offset})
+		current = next
+	}
+	return periods
+}
+
+type offsetPeriod struct {
+	from   time.Time
+	to     time.Time
+	offset int
+}
+
+// offsetPeriods splits [from, to) into the periods which have the same difference of the UTC offsets.
+func offsetPeriods(from, to time.Time, base *time.Location, loc *time.Location) []*offsetPeriod {
+	periods := make([]*offsetPeriod, 0, 1)
+	current := &offsetPeriod{from: from, offset: timeZoneOffset(from, base, loc)}
+	for t := from.Truncate(time.Hour).Add(time.Hour); t.Before(to); t = t.Add(time.Hour) {
+		offset := timeZoneOffset(t, base, loc)
+		if offset == current.offset {
+			continue
+		}
+		lower, upper := t.Add(-time.Hour), t
+		if lower.Before(from) {
+			lower = from
+		}
+		for upper.Sub(lower) > time.Minute {
+			mid := lower.Add(upper.Sub(lower) / 2).Truncate(time.Minute)
+			if !mid.After(lower) {
+				break
+			}
+			if timeZoneOffset(mid, base, loc) == current.offset {
+				lower = mid
+			} else {
+				upper = mid
+			}
+		}
+		current.to = upper
+		periods = append(periods, current)
+		current = &offsetPeriod{from: upper, offset: offset}
+	}
+	current.to = to
+	return append(periods, current)
+}
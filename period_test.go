@@ -0,0 +1,150 @@
+package rules2cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverterConvertPeriod(t *testing.T) {
+	la := Must(time.LoadLocation("America/Los_Angeles"))
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      la,
+	}
+	expr := Must(rules2cron.ParseExpression("cron(0 12 * * ? *)"))
+	actual, err := converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	expected := []struct {
+		crontab string
+		from    time.Time
+		to      time.Time
+	}{
+		{"0 4 * * *", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 3, 13, 10, 0, 0, 0, time.UTC)},
+		{"0 5 * * *", time.Date(2022, 3, 13, 10, 0, 0, 0, time.UTC), time.Date(2022, 11, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2022, 11, 6, 9, 0, 0, 0, time.UTC), time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for i, e := range expected {
+		require.Equal(t, e.crontab, actual[i].String())
+		require.True(t, e.from.Equal(actual[i].From), "from[%d] = %s", i, actual[i].From)
+		require.True(t, e.to.Equal(actual[i].To), "to[%d] = %s", i, actual[i].To)
+	}
+}
+
+func TestConverterConvertPeriodWithoutTransition(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}
+	expr := Must(rules2cron.ParseExpression("cron(0 12 * * ? *)"))
+	actual, err := converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "0 21 * * *", actual[0].String())
+}
+
+func TestConverterConvertPeriodAcrossYears(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}
+	expr := Must(rules2cron.ParseExpression("cron(0 0 * * ? 2023)"))
+	actual, err := converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "0 9 * * *", actual[0].String())
+	require.True(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Equal(actual[0].From), "from = %s", actual[0].From)
+	require.True(t, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC).Equal(actual[0].To), "to = %s", actual[0].To)
+
+	expr = Must(rules2cron.ParseExpression("cron(0 0 * * ? 2024)"))
+	_, err = converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, rules2cron.ErrorCodeOutOfTarget, rules2cron.CodeOf(err))
+}
+
+func TestConverterConvertPeriodMonthly(t *testing.T) {
+	cases := []struct {
+		scheduleExpression string
+		timeZone           *time.Location
+		expected           []string
+	}{
+		{
+			scheduleExpression: "cron(0 0 L * ? *)",
+			timeZone:           time.UTC,
+			expected:           []string{"0 0 31 1 *", "0 0 28 2 *", "0 0 31 3 *"},
+		},
+		{
+			scheduleExpression: "cron(0 0 L * ? *)",
+			timeZone:           Must(time.LoadLocation("America/Los_Angeles")),
+			expected:           []string{"0 16 30 1 *", "0 16 27 2 *", "0 17 30 3 *"},
+		},
+		{
+			scheduleExpression: "cron(0 12 ? * 3#2 *)",
+			timeZone:           time.UTC,
+			expected:           []string{"0 12 11 1 *", "0 12 8 2 *", "0 12 8 3 *"},
+		},
+		{
+			scheduleExpression: "cron(0 12 LW 2 ? *)",
+			timeZone:           time.UTC,
+			expected:           []string{"0 12 28 2 *"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression+" "+c.timeZone.String(), func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      c.timeZone,
+			}
+			expr := Must(rules2cron.ParseExpression(c.scheduleExpression))
+			schedules, err := converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			actual := make([]string, 0, len(schedules))
+			for _, s := range schedules {
+				actual = append(actual, s.String())
+			}
+			require.EqualValues(t, c.expected, actual)
+		})
+	}
+}
+
+func TestConverterConvertPeriodMonthEnd(t *testing.T) {
+	cases := []struct {
+		scheduleExpression string
+		timeZone           *time.Location
+		expected           []string
+	}{
+		{
+			scheduleExpression: "cron(0 20 L * ? *)",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+			expected:           []string{"0 5 1 5 *", "0 5 1 6 *", "0 5 1 7 *"},
+		},
+		{
+			scheduleExpression: "cron(0 20 LW * ? *)",
+			timeZone:           Must(time.LoadLocation("Asia/Tokyo")),
+			expected:           []string{"0 5 30 4 *", "0 5 1 6 *", "0 5 1 7 *"},
+		},
+		{
+			scheduleExpression: "cron(0/20 23 L * ? *)",
+			timeZone:           Must(time.LoadLocation("Asia/Kolkata")),
+			expected:           []string{"30-50/20 4 1 5 *", "10 5 1 5 *", "30-50/20 4 1 6 *", "10 5 1 6 *", "30-50/20 4 1 7 *", "10 5 1 7 *"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression+" "+c.timeZone.String(), func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      c.timeZone,
+			}
+			expr := Must(rules2cron.ParseExpression(c.scheduleExpression))
+			schedules, err := converter.ConvertPeriod(expr, time.UTC, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			actual := make([]string, 0, len(schedules))
+			for _, s := range schedules {
+				actual = append(actual, s.String())
+			}
+			require.EqualValues(t, c.expected, actual)
+		})
+	}
+}
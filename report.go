@@ -0,0 +1,80 @@
+package rules2cron
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ConversionReport is the summary of a run, how many rules were converted and why the others were skipped.
+type ConversionReport struct {
+	Converted int
+	Skipped   []*SkippedRule
+}
+
+// SkippedRule is a rule which could not be converted.
+type SkippedRule struct {
+	Name string
+	Err  error
+}
+
+// Code returns the error code of the reason.
+func (r *SkippedRule) Code() ErrorCode {
+	return CodeOf(r.Err)
+}
+
+func (r *ConversionReport) addConverted() {
+	r.Converted++
+}
+
+func (r *ConversionReport) addSkipped(name string, err error) {
+	r.Skipped = append(r.Skipped, &SkippedRule{Name: name, Err: err})
+}
+
+// CountByCode returns the number of the skipped rules for each error code.
+func (r *ConversionReport) CountByCode() map[ErrorCode]int {
+	counts := make(map[ErrorCode]int)
+	for _, s := range r.Skipped {
+		counts[s.Code()]++
+	}
+	return counts
+}
+
+// Summary returns the lines which summarize the report.
+func (r *ConversionReport) Summary() []string {
+	lines := []string{fmt.Sprintf("%d rules converted, %d rules skipped", r.Converted, len(r.Skipped))}
+	names := make(map[ErrorCode][]string)
+	for _, s := range r.Skipped {
+		names[s.Code()] = append(names[s.Code()], s.Name)
+	}
+	codes := make([]string, 0, len(names))
+	for code := range names {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		n := names[ErrorCode(code)]
+		lines = append(lines, fmt.Sprintf("  %s: %d (%s)", code, len(n), strings.Join(n, ", ")))
+	}
+	return lines
+}
+
+func (r *ConversionReport) log() {
+	level := "[info]"
+	if len(r.Skipped) > 0 {
+		level = "[warn]"
+	}
+	for _, line := range r.Summary() {
+		log.Println(level, line)
+	}
+}
+
+// SkippedRulesError is returned by App when AppOptions.FailOnSkip is true and some rules were skipped.
+type SkippedRulesError struct {
+	Report *ConversionReport
+}
+
+func (e *SkippedRulesError) Error() string {
+	return fmt.Sprintf("%d rules could not be converted", len(e.Report.Skipped))
+}
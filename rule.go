@@ -0,0 +1,69 @@
+package rules2cron
+
+import (
+	"strings"
+	"time"
+)
+
+// Rule is a scheduled rule of EventBridge, or a schedule of EventBridge Scheduler.
+type Rule struct {
+	Name string
+	// DeployedName is the name of the rule in AWS when it differs from Name,
+	// such as the rules declared in the templates which are named with the logical ID. Empty if unknown.
+	DeployedName string
+	// Unnamed reports whether the name in AWS is unknown until deployed,
+	// such as the rules of the templates without the Name property, named by CloudFormation or Terraform.
+	Unnamed            bool
+	Arn                string
+	State              string
+	ScheduleExpression string
+	Description        string
+	// EventBusName is the name of the event bus of the EventBridge rule, empty for the schedules of EventBridge Scheduler.
+	EventBusName string
+	// TimeZone is the time zone in which ScheduleExpression is evaluated, UTC for the rules of EventBridge.
+	TimeZone *time.Location
+	// Targets is the targets invoked by the rule.
+	Targets []*Target
+	// Account and Region are the account ID and the region of the rule, set by ScanSource.
+	Account string
+	Region  string
+}
+
+// QualifiedName returns the name which is unique across the accounts, the regions and the event buses,
+// prefixed with the event bus name unless it is the default event bus, and with the account and the region if tagged,
+// such as `123456789012/ap-northeast-1/custom/name`.
+func (r *Rule) QualifiedName() string {
+	return qualifiedName(r.Account, r.Region, r.EventBusName, r.Name)
+}
+
+func qualifiedName(account, region, eventBusName, name string) string {
+	parts := make([]string, 0, 4)
+	for _, tag := range []string{account, region} {
+		if tag != "" {
+			parts = append(parts, tag)
+		}
+	}
+	if eventBusName != "" && eventBusName != "default" {
+		parts = append(parts, eventBusName)
+	}
+	return strings.Join(append(parts, name), "/")
+}
+
+// Target is a target of the rule, such as a Lambda function, an ECS cluster, a Step Functions state machine or an SQS queue.
+type Target struct {
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Arn     string `json:"arn" yaml:"arn"`
+	RoleArn string `json:"role_arn,omitempty" yaml:"role_arn,omitempty"`
+	// TaskDefinitionArn is the task definition of the ECS task, Arn is the ECS cluster.
+	TaskDefinitionArn string `json:"task_definition_arn,omitempty" yaml:"task_definition_arn,omitempty"`
+	// Input, InputPath and InputTransformer are the input passed to the target, the event if all of them are empty.
+	Input            string            `json:"input,omitempty" yaml:"input,omitempty"`
+	InputPath        string            `json:"input_path,omitempty" yaml:"input_path,omitempty"`
+	InputTransformer *InputTransformer `json:"input_transformer,omitempty" yaml:"input_transformer,omitempty"`
+}
+
+// InputTransformer is the input transformer of the target of EventBridge.
+type InputTransformer struct {
+	InputPathsMap map[string]string `json:"input_paths_map,omitempty" yaml:"input_paths_map,omitempty"`
+	InputTemplate string            `json:"input_template" yaml:"input_template"`
+}
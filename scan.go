@@ -0,0 +1,217 @@
+package rules2cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// ScanTarget is a region of an account to list the rules.
+type ScanTarget struct {
+	// Region is the region, the region of the profile or AWS_DEFAULT_REGION if empty.
+	Region string
+	// Profile is the profile of the shared config, the default credentials if empty.
+	Profile string
+	// RoleArn is the role assumed with STS AssumeRole on the credentials of Profile, if set.
+	RoleArn string
+}
+
+func (t ScanTarget) String() string {
+	account := t.RoleArn
+	if account == "" {
+		account = t.Profile
+	}
+	if account == "" {
+		account = "default"
+	}
+	if t.Region == "" {
+		return account
+	}
+	return account + "@" + t.Region
+}
+
+// ScanTargets returns the targets of each region for each account, the accounts are the profiles and the roles assumed on the default credentials.
+// The empty regions, or the empty profiles and roles, are the default of the config.
+func ScanTargets(regions, profiles, roleArns []string) []ScanTarget {
+	accounts := make([]ScanTarget, 0, len(profiles)+len(roleArns))
+	for _, profile := range profiles {
+		accounts = append(accounts, ScanTarget{Profile: profile})
+	}
+	for _, roleArn := range roleArns {
+		accounts = append(accounts, ScanTarget{RoleArn: roleArn})
+	}
+	if len(accounts) == 0 {
+		accounts = append(accounts, ScanTarget{})
+	}
+	if len(regions) == 0 {
+		regions = []string{""}
+	}
+	targets := make([]ScanTarget, 0, len(accounts)*len(regions))
+	for _, account := range accounts {
+		for _, region := range regions {
+			target := account
+			target.Region = region
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// ScanSource is the source which lists the rules of the targets concurrently with the bounded number of workers.
+// The rules are tagged with the account and the region, and passed to fn in the order of the targets.
+type ScanSource struct {
+	targets     []ScanTarget
+	sources     []Source
+	regions     []string
+	concurrency int
+}
+
+// NewScanSource returns the source which lists the rules of the targets with AWSSource, concurrency is 4 if not positive.
+func NewScanSource(ctx context.Context, targets []ScanTarget, concurrency int, optFns ...func(*AWSSourceOptions)) (*ScanSource, error) {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	src := &ScanSource{
+		targets:     targets,
+		sources:     make([]Source, 0, len(targets)),
+		regions:     make([]string, 0, len(targets)),
+		concurrency: concurrency,
+	}
+	for _, target := range targets {
+		awsCfg, err := loadAWSConfig(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		src.sources = append(src.sources, NewAWSSource(awsCfg, optFns...))
+		src.regions = append(src.regions, awsCfg.Region)
+	}
+	return src, nil
+}
+
+// Rules calls fn for each rule of the targets, it fails if any target fails.
+func (src *ScanSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([][]*Rule, len(src.sources))
+	errs := make([]error, len(src.sources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < src.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				log.Printf("[debug] scan %s", src.targets[i])
+				err := src.sources[i].Rules(ctx, func(rule *Rule) error {
+					rule.Region = src.regions[i]
+					rule.Account = accountOf(rule.Arn, src.targets[i].RoleArn)
+					results[i] = append(results[i], rule)
+					return nil
+				})
+				if err != nil {
+					errs[i] = fmt.Errorf("%s: %w", src.targets[i], err)
+					cancel()
+				}
+			}
+		}()
+	}
+	for i := range src.sources {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	var canceled error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		canceled = err
+	}
+	if canceled != nil {
+		return canceled
+	}
+	for _, rules := range results {
+		for _, rule := range rules {
+			if err := fn(rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// accountOf returns the account ID of the ARN, or the ARN of the role if the ARN is empty.
+func accountOf(arns ...string) string {
+	for _, arn := range arns {
+		if parts := strings.SplitN(arn, ":", 6); len(parts) == 6 && parts[4] != "" {
+			return parts[4]
+		}
+	}
+	return ""
+}
+
+// loadAWSConfig loads the config of the target.
+// EVENTBRIDGE_ENDPOINT, SCHEDULER_ENDPOINT and STS_ENDPOINT replace the endpoints, such as the local stubs.
+func loadAWSConfig(ctx context.Context, target ScanTarget) (aws.Config, error) {
+	opts := make([]func(*config.LoadOptions) error, 0)
+
+	if target.Region != "" {
+		opts = append(opts, config.WithRegion(target.Region))
+	} else if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	if target.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(target.Profile))
+	}
+	endpoints := make(map[string]string)
+	if endpoint := os.Getenv("EVENTBRIDGE_ENDPOINT"); endpoint != "" {
+		endpoints[eventbridge.ServiceID] = endpoint
+	}
+	if endpoint := os.Getenv("SCHEDULER_ENDPOINT"); endpoint != "" {
+		endpoints[scheduler.ServiceID] = endpoint
+	}
+	if endpoint := os.Getenv("STS_ENDPOINT"); endpoint != "" {
+		endpoints[sts.ServiceID] = endpoint
+	}
+	if len(endpoints) > 0 {
+		opts = append(opts, config.WithEndpointResolverWithOptions(
+			aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+				if endpoint, ok := endpoints[service]; ok {
+					return aws.Endpoint{
+						URL:           endpoint,
+						PartitionID:   "aws",
+						SigningRegion: region,
+					}, nil
+				}
+				return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+			})))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if target.RoleArn != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), target.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "rules2cron"
+		})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return awsCfg, nil
+}
@@ -0,0 +1,146 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTargets(t *testing.T) {
+	targets := rules2cron.ScanTargets(
+		[]string{"ap-northeast-1", "us-west-2"},
+		[]string{"prod"},
+		[]string{"arn:aws:iam::210987654321:role/scan"},
+	)
+	actual := make([]string, 0, len(targets))
+	for _, target := range targets {
+		actual = append(actual, target.String())
+	}
+	require.Equal(t, []string{
+		"prod@ap-northeast-1",
+		"prod@us-west-2",
+		"arn:aws:iam::210987654321:role/scan@ap-northeast-1",
+		"arn:aws:iam::210987654321:role/scan@us-west-2",
+	}, actual)
+	require.Equal(t, []rules2cron.ScanTarget{{}}, rules2cron.ScanTargets(nil, nil, nil))
+}
+
+var stubCredentialPattern = regexp.MustCompile(`Credential=([^/]+)/[^/]+/([^/]+)/`)
+
+// newStubScanServer returns the stub server of STS, EventBridge and EventBridge Scheduler.
+// AssumeRole issues the credentials of the account 210987654321, and the other credentials are of the account 123456789012.
+// ListRules returns a rule with the ARN of the account and the region of the request.
+func newStubScanServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			r.ParseForm()
+			if r.Form.Get("Action") != "AssumeRole" {
+				http.Error(w, "unknown action", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/xml")
+			fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>ASIAASSUMED</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey><SessionToken>TOKEN</SessionToken><Expiration>%s</Expiration></Credentials>
+<AssumedRoleUser><Arn>%s</Arn><AssumedRoleId>AROA:rules2cron</AssumedRoleId></AssumedRoleUser>
+</AssumeRoleResult></AssumeRoleResponse>`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339), "arn:aws:sts::210987654321:assumed-role/scan/rules2cron")
+			return
+		}
+		m := stubCredentialPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+		if m == nil {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+		account, region := "123456789012", m[2]
+		if m[1] == "ASIAASSUMED" {
+			account = "210987654321"
+		}
+		output := map[string]interface{}{}
+		switch r.Header.Get("X-Amz-Target") {
+		case "AWSEvents.ListEventBuses":
+			output["EventBuses"] = []map[string]string{{"Name": "default"}}
+		case "AWSEvents.ListRules":
+			output["Rules"] = []map[string]string{{
+				"Name":               "daily",
+				"Arn":                fmt.Sprintf("arn:aws:events:%s:%s:rule/daily", region, account),
+				"EventBusName":       "default",
+				"State":              "ENABLED",
+				"ScheduleExpression": "cron(0 18 * * ? *)",
+			}}
+		default:
+			output["Schedules"] = []interface{}{}
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(output)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAppScan(t *testing.T) {
+	server := newStubScanServer(t)
+	dir := t.TempDir()
+	credentials := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentials, []byte("[prod]\naws_access_key_id = AKIDPROD\naws_secret_access_key = SECRET\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDDEFAULT")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	t.Setenv("EVENTBRIDGE_ENDPOINT", server.URL)
+	t.Setenv("SCHEDULER_ENDPOINT", server.URL)
+	t.Setenv("STS_ENDPOINT", server.URL)
+
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Regions = []string{"ap-northeast-1", "us-west-2"}
+		o.Profiles = []string{"prod"}
+		o.RoleArns = []string{"arn:aws:iam::210987654321:role/scan"}
+		o.Concurrency = 2
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Equal(t, "0 18 * * *\t123456789012/ap-northeast-1/daily\n"+
+		"0 18 * * *\t123456789012/us-west-2/daily\n"+
+		"0 18 * * *\t210987654321/ap-northeast-1/daily\n"+
+		"0 18 * * *\t210987654321/us-west-2/daily\n", buf.String())
+}
+
+func TestAppScanWithoutSchedulerEndpoint(t *testing.T) {
+	server := newStubScanServer(t)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDDEFAULT")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	t.Setenv("EVENTBRIDGE_ENDPOINT", server.URL)
+	t.Setenv("SCHEDULER_ENDPOINT", "")
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Regions = []string{"ap-northeast-1"}
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Equal(t, "0 18 * * *\t123456789012/ap-northeast-1/daily\n", buf.String())
+	require.Contains(t, logs.String(), "[warn] the schedules of EventBridge Scheduler are skipped, since EVENTBRIDGE_ENDPOINT is set without SCHEDULER_ENDPOINT")
+}
@@ -2,6 +2,8 @@ package rules2cron
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type Schedule struct {
@@ -15,3 +17,69 @@ type Schedule struct {
 func (s *Schedule) String() string {
 	return fmt.Sprintf("%s %s %s %s %s", s.Minute, s.Hour, s.DayOfMonth, s.Month, s.DayOfWeek)
 }
+
+// formatCrontabField formats items of the crontab field.
+// items are limited to CronItemAll, CronItemValue and CronItemRange, and the values are crontab's one.
+// CronItemLast is formatted as L only for the expressions of EventBridge.
+func formatCrontabField(items []*CronItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		var part string
+		switch item.Kind {
+		case CronItemLast:
+			part = "L"
+		case CronItemRange:
+			part = fmt.Sprintf("%d-%d", item.Start, item.End)
+		case CronItemValue:
+			part = strconv.Itoa(item.Start)
+		default:
+			part = "*"
+		}
+		if item.Step > 0 {
+			part += "/" + strconv.Itoa(item.Step)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+// crontabFields is the fields of crontab before formatting, built from CronExpression.
+type crontabFields struct {
+	minute     []*CronItem
+	hour       []*CronItem
+	dayOfMonth []*CronItem
+	month      []*CronItem
+	dayOfWeek  []*CronItem
+
+	// resolvedInMonth reports whether the day-of-month is resolved in the reference month, such as L, W and #.
+	resolvedInMonth bool
+}
+
+func (f *crontabFields) clone() *crontabFields {
+	cloned := *f
+	return &cloned
+}
+
+func (f *crontabFields) schedule() *Schedule {
+	return &Schedule{
+		Minute:     formatCrontabField(f.minute),
+		Hour:       formatCrontabField(f.hour),
+		DayOfMonth: formatCrontabField(f.dayOfMonth),
+		Month:      formatCrontabField(f.month),
+		DayOfWeek:  formatCrontabField(f.dayOfWeek),
+	}
+}
+
+// isDaySensitive reports whether any day fields are restricted, so that carrying over the day changes the schedule.
+func (f *crontabFields) isDaySensitive() bool {
+	return !isAllItems(f.dayOfMonth) || !isAllItems(f.month) || !isAllItems(f.dayOfWeek)
+}
+
+func isAllItems(items []*CronItem) bool {
+	for _, item := range items {
+		if item.Kind == CronItemAll && item.Step <= 1 {
+			return true
+		}
+	}
+	return false
+}
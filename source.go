@@ -0,0 +1,341 @@
+package rules2cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/smithy-go"
+	"github.com/mashiike/rules2cron/internal/eventbridgex"
+)
+
+// Source is the source of the scheduled rules.
+type Source interface {
+	// Rules calls fn for each scheduled rule, the rules which have no schedule expression are skipped.
+	Rules(ctx context.Context, fn func(*Rule) error) error
+}
+
+// AWSSource is the source of the scheduled rules of EventBridge and the schedules of EventBridge Scheduler.
+type AWSSource struct {
+	client          *eventbridge.Client
+	schedulerClient *scheduler.Client
+	options         AWSSourceOptions
+}
+
+// AWSSourceOptions is the options for AWSSource.
+type AWSSourceOptions struct {
+	// EventBusNames is the event buses to list the rules, all the event buses if empty.
+	// It does not affect the schedules of EventBridge Scheduler.
+	EventBusNames []string
+
+	// NamePrefix is passed to ListRules and ListSchedules to list the rules whose name starts with it.
+	NamePrefix string
+
+	// Tags selects the rules which have all of the tags, resolved with ListTagsForResource for each rule.
+	Tags map[string]string
+
+	// WithTargets lists the targets of each rule of EventBridge with ListTargetsByRule.
+	// The target of the schedule of EventBridge Scheduler is always set.
+	WithTargets bool
+
+	// WithoutScheduler skips the schedules of EventBridge Scheduler, ListSchedules and GetSchedule are not called.
+	WithoutScheduler bool
+}
+
+// NewAWSSource returns the source which lists the rules with the config.
+func NewAWSSource(awsCfg aws.Config, optFns ...func(*AWSSourceOptions)) *AWSSource {
+	var options AWSSourceOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	return &AWSSource{
+		client:          eventbridge.NewFromConfig(awsCfg),
+		schedulerClient: scheduler.NewFromConfig(awsCfg),
+		options:         options,
+	}
+}
+
+// Rules calls fn for each scheduled rule of EventBridge and each schedule of EventBridge Scheduler.
+func (src *AWSSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	if err := src.eachEventBridgeRule(ctx, fn); err != nil {
+		return err
+	}
+	if src.options.WithoutScheduler {
+		return nil
+	}
+	return src.eachSchedulerSchedule(ctx, fn)
+}
+
+func (src *AWSSource) eachEventBridgeRule(ctx context.Context, fn func(*Rule) error) error {
+	busNames, err := src.eventBusNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, busName := range busNames {
+		if err := src.eachEventBusRule(ctx, busName, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventBusNames returns EventBusNames of the options, or the names of all the event buses.
+func (src *AWSSource) eventBusNames(ctx context.Context) ([]string, error) {
+	if len(src.options.EventBusNames) > 0 {
+		return src.options.EventBusNames, nil
+	}
+	names := make([]string, 0)
+	p := eventbridgex.NewListEventBusesPaginator(src.client, &eventbridge.ListEventBusesInput{})
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, bus := range output.EventBuses {
+			names = append(names, aws.ToString(bus.Name))
+		}
+	}
+	return names, nil
+}
+
+func (src *AWSSource) eachEventBusRule(ctx context.Context, busName string, fn func(*Rule) error) error {
+	input := &eventbridge.ListRulesInput{
+		EventBusName: aws.String(busName),
+	}
+	if src.options.NamePrefix != "" {
+		input.NamePrefix = aws.String(src.options.NamePrefix)
+	}
+	p := eventbridgex.NewListRulesPaginator(src.client, input)
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, rule := range output.Rules {
+			if rule.ScheduleExpression == nil {
+				log.Printf("[debug] rule %s is not scheduled rule, skip", *rule.Arn)
+				continue
+			}
+			ok, err := src.hasTags(ctx, aws.ToString(rule.Arn), src.eventBridgeTags)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				log.Printf("[debug] rule %s does not have the tags, skip", *rule.Arn)
+				continue
+			}
+			r := &Rule{
+				Name:               *rule.Name,
+				Arn:                aws.ToString(rule.Arn),
+				State:              string(rule.State),
+				ScheduleExpression: *rule.ScheduleExpression,
+				Description:        aws.ToString(rule.Description),
+				EventBusName:       eventBusName(rule.EventBusName, busName),
+				TimeZone:           time.UTC,
+			}
+			if src.options.WithTargets {
+				if r.Targets, err = src.listTargets(ctx, r); err != nil {
+					return err
+				}
+			}
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (src *AWSSource) eachSchedulerSchedule(ctx context.Context, fn func(*Rule) error) error {
+	input := &scheduler.ListSchedulesInput{}
+	if src.options.NamePrefix != "" {
+		input.NamePrefix = aws.String(src.options.NamePrefix)
+	}
+	p := scheduler.NewListSchedulesPaginator(src.schedulerClient, input)
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			if isSchedulerUnavailable(err) {
+				log.Printf("[warn] the schedules of EventBridge Scheduler are skipped: %s", err.Error())
+				return nil
+			}
+			return err
+		}
+		for _, summary := range output.Schedules {
+			ok, err := src.hasTags(ctx, aws.ToString(summary.Arn), src.schedulerTags)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				log.Printf("[debug] schedule %s does not have the tags, skip", aws.ToString(summary.Arn))
+				continue
+			}
+			schedule, err := src.schedulerClient.GetSchedule(ctx, &scheduler.GetScheduleInput{
+				Name:      summary.Name,
+				GroupName: summary.GroupName,
+			})
+			if err != nil {
+				return err
+			}
+			name := scheduleName(schedule.GroupName, schedule.Name)
+			if schedule.ScheduleExpression == nil {
+				log.Printf("[debug] schedule %s has no schedule expression, skip", *schedule.Arn)
+				continue
+			}
+			loc, err := loadScheduleLocation(aws.ToString(schedule.ScheduleExpressionTimezone))
+			if err != nil {
+				log.Printf("[warn] schedule %s: %s", name, err.Error())
+				continue
+			}
+			now := time.Now()
+			if schedule.EndDate != nil && schedule.EndDate.Before(now) {
+				log.Printf("[warn] schedule %s: expired at %s, it does not fire any more", name, schedule.EndDate.Format(time.RFC3339))
+			} else if schedule.StartDate != nil && schedule.StartDate.After(now) {
+				log.Printf("[warn] schedule %s: starts at %s, it does not fire until then", name, schedule.StartDate.Format(time.RFC3339))
+			}
+			r := &Rule{
+				Name:               name,
+				Arn:                aws.ToString(schedule.Arn),
+				State:              string(schedule.State),
+				ScheduleExpression: *schedule.ScheduleExpression,
+				Description:        aws.ToString(schedule.Description),
+				TimeZone:           loc,
+			}
+			if schedule.Target != nil {
+				target := &Target{
+					Arn:     aws.ToString(schedule.Target.Arn),
+					RoleArn: aws.ToString(schedule.Target.RoleArn),
+					Input:   aws.ToString(schedule.Target.Input),
+				}
+				if schedule.Target.EcsParameters != nil {
+					target.TaskDefinitionArn = aws.ToString(schedule.Target.EcsParameters.TaskDefinitionArn)
+				}
+				r.Targets = []*Target{target}
+			}
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSchedulerUnavailable reports whether the error means EventBridge Scheduler can not be used,
+// such as the permissions are not granted or the region does not support it.
+func isSchedulerUnavailable(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDeniedException", "AccessDenied", "UnknownEndpoint", "UnrecognizedClientException":
+			return true
+		}
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// listTargets lists the targets of the rule of EventBridge.
+func (src *AWSSource) listTargets(ctx context.Context, rule *Rule) ([]*Target, error) {
+	targets := make([]*Target, 0)
+	p := eventbridgex.NewListTargetsByRulePaginator(src.client, &eventbridge.ListTargetsByRuleInput{
+		Rule:         aws.String(rule.Name),
+		EventBusName: aws.String(rule.EventBusName),
+	})
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list targets of %s: %w", rule.Arn, err)
+		}
+		for _, t := range output.Targets {
+			target := &Target{
+				ID:        aws.ToString(t.Id),
+				Arn:       aws.ToString(t.Arn),
+				RoleArn:   aws.ToString(t.RoleArn),
+				Input:     aws.ToString(t.Input),
+				InputPath: aws.ToString(t.InputPath),
+			}
+			if t.EcsParameters != nil {
+				target.TaskDefinitionArn = aws.ToString(t.EcsParameters.TaskDefinitionArn)
+			}
+			if t.InputTransformer != nil {
+				target.InputTransformer = &InputTransformer{
+					InputPathsMap: t.InputTransformer.InputPathsMap,
+					InputTemplate: aws.ToString(t.InputTransformer.InputTemplate),
+				}
+			}
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// hasTags reports whether the resource has all of Tags of the options, listing the tags only if Tags is set.
+func (src *AWSSource) hasTags(ctx context.Context, arn string, listTags func(context.Context, string) (map[string]string, error)) (bool, error) {
+	if len(src.options.Tags) == 0 {
+		return true, nil
+	}
+	tags, err := listTags(ctx, arn)
+	if err != nil {
+		return false, fmt.Errorf("list tags of %s: %w", arn, err)
+	}
+	return matchTags(src.options.Tags, tags), nil
+}
+
+func (src *AWSSource) eventBridgeTags(ctx context.Context, arn string) (map[string]string, error) {
+	output, err := src.client.ListTagsForResource(ctx, &eventbridge.ListTagsForResourceInput{ResourceARN: aws.String(arn)})
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(output.Tags))
+	for _, tag := range output.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (src *AWSSource) schedulerTags(ctx context.Context, arn string) (map[string]string, error) {
+	output, err := src.schedulerClient.ListTagsForResource(ctx, &scheduler.ListTagsForResourceInput{ResourceArn: aws.String(arn)})
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(output.Tags))
+	for _, tag := range output.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// eventBusName returns the event bus name of the rule, busName if the rule does not have it.
+func eventBusName(name *string, busName string) string {
+	if name == nil || *name == "" {
+		return busName
+	}
+	return *name
+}
+
+// loadScheduleLocation loads ScheduleExpressionTimezone, UTC if empty.
+func loadScheduleLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("can not load location %s: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// scheduleName returns the schedule name, prefixed with the schedule group name unless it is the default group.
+func scheduleName(groupName, name *string) string {
+	group := aws.ToString(groupName)
+	if group == "" || group == "default" {
+		return aws.ToString(name)
+	}
+	return group + "/" + aws.ToString(name)
+}
@@ -0,0 +1,399 @@
+package rules2cron
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CloudFormationSource is the source of the schedules declared in the CloudFormation or SAM template, in JSON or YAML.
+// The rules are AWS::Events::Rule, AWS::Scheduler::Schedule, and Schedule and ScheduleV2 events of SAM,
+// named with the logical ID, the logical ID of the function followed by the event name for SAM.
+// Ref and Fn::Sub of the parameters are resolved with the given values or the default values.
+type CloudFormationSource struct {
+	r          io.Reader
+	parameters map[string]string
+}
+
+// NewCloudFormationSource returns the source which reads the template from r, parameters override the default values.
+func NewCloudFormationSource(r io.Reader, parameters map[string]string) *CloudFormationSource {
+	return &CloudFormationSource{r: r, parameters: parameters}
+}
+
+// Rules calls fn for each schedule declared in the template.
+func (src *CloudFormationSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	data, err := io.ReadAll(src.r)
+	if err != nil {
+		return err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("read template: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("read template: must be a mapping")
+	}
+	t := &cfnTemplate{parameters: make(map[string]string)}
+	doc := root.Content[0]
+	if params, ok := mappingValue(doc, "Parameters"); ok {
+		for _, kv := range mappingPairs(params) {
+			if def, ok := cfnNodeValue(kv.value).(map[string]interface{})["Default"]; ok {
+				t.parameters[kv.key] = fmt.Sprint(def)
+			}
+		}
+	}
+	for k, v := range src.parameters {
+		t.parameters[k] = v
+	}
+	resources, ok := mappingValue(doc, "Resources")
+	if !ok {
+		return nil
+	}
+	for _, kv := range mappingPairs(resources) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resource, _ := cfnNodeValue(kv.value).(map[string]interface{})
+		for _, rule := range t.rules(kv.key, resource) {
+			if err := fn(rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type cfnTemplate struct {
+	parameters map[string]string
+}
+
+// rules returns the schedules of the resource.
+func (t *cfnTemplate) rules(logicalID string, resource map[string]interface{}) []*Rule {
+	props, _ := resource["Properties"].(map[string]interface{})
+	switch resource["Type"] {
+	case "AWS::Events::Rule":
+		rule := t.rule(logicalID, props, "ScheduleExpression")
+		if rule == nil {
+			return nil
+		}
+		rule.EventBusName = "default"
+		if bus, ok := t.resolve(props["EventBusName"]); ok {
+			rule.EventBusName = bus
+		}
+		targets, _ := props["Targets"].([]interface{})
+		for _, target := range targets {
+			target, _ := target.(map[string]interface{})
+			rule.Targets = append(rule.Targets, &Target{
+				ID:      t.reference(target["Id"]),
+				Arn:     t.reference(target["Arn"]),
+				RoleArn: t.reference(target["RoleArn"]),
+			})
+		}
+		return []*Rule{rule}
+	case "AWS::Scheduler::Schedule":
+		rule := t.rule(logicalID, props, "ScheduleExpression")
+		if rule == nil {
+			return nil
+		}
+		if !t.setScheduleProperties(rule, props) {
+			return nil
+		}
+		if target, ok := props["Target"].(map[string]interface{}); ok {
+			rule.Targets = []*Target{{Arn: t.reference(target["Arn"]), RoleArn: t.reference(target["RoleArn"])}}
+		}
+		return []*Rule{rule}
+	case "AWS::Serverless::Function", "AWS::Serverless::StateMachine":
+		events, _ := props["Events"].(map[string]interface{})
+		names := make([]string, 0, len(events))
+		for name := range events {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rules := make([]*Rule, 0)
+		for _, name := range names {
+			event, _ := events[name].(map[string]interface{})
+			eventProps, _ := event["Properties"].(map[string]interface{})
+			var rule *Rule
+			switch event["Type"] {
+			case "Schedule":
+				rule = t.rule(logicalID+name, eventProps, "Schedule")
+				if rule == nil {
+					continue
+				}
+				rule.EventBusName = "default"
+				if enabled, ok := eventProps["Enabled"].(bool); ok && !enabled && eventProps["State"] == nil {
+					rule.State = "DISABLED"
+				}
+			case "ScheduleV2":
+				rule = t.rule(logicalID+name, eventProps, "ScheduleExpression")
+				if rule == nil || !t.setScheduleProperties(rule, eventProps) {
+					continue
+				}
+			default:
+				continue
+			}
+			rule.Targets = []*Target{{Arn: logicalID}}
+			rules = append(rules, rule)
+		}
+		return rules
+	default:
+		return nil
+	}
+}
+
+// rule returns the rule of the properties, nil if the schedule expression is not found or can not be resolved.
+func (t *cfnTemplate) rule(name string, props map[string]interface{}, expressionKey string) *Rule {
+	v, ok := props[expressionKey]
+	if !ok {
+		log.Printf("[debug] resource %s is not scheduled, skip", name)
+		return nil
+	}
+	expr, ok := t.resolve(v)
+	if !ok {
+		log.Printf("[warn] resource %s: can not resolve %s, skip", name, expressionKey)
+		return nil
+	}
+	rule := &Rule{
+		Name:               name,
+		State:              "ENABLED",
+		ScheduleExpression: expr,
+		TimeZone:           time.UTC,
+	}
+	if state, ok := t.resolve(props["State"]); ok {
+		rule.State = state
+	}
+	if description, ok := t.resolve(props["Description"]); ok {
+		rule.Description = description
+	}
+	if deployed, ok := t.resolve(props["Name"]); ok {
+		rule.DeployedName = deployed
+	} else {
+		rule.Unnamed = true
+	}
+	return rule
+}
+
+// setScheduleProperties sets the time zone and the group of the schedule of EventBridge Scheduler, false if the time zone can not be loaded.
+func (t *cfnTemplate) setScheduleProperties(rule *Rule, props map[string]interface{}) bool {
+	if group, ok := t.resolve(props["GroupName"]); ok && rule.DeployedName != "" {
+		rule.DeployedName = scheduleName(&group, &rule.DeployedName)
+	}
+	tz, _ := t.resolve(props["ScheduleExpressionTimezone"])
+	loc, err := loadScheduleLocation(tz)
+	if err != nil {
+		log.Printf("[warn] resource %s: %s", rule.Name, err.Error())
+		return false
+	}
+	rule.TimeZone = loc
+	return true
+}
+
+var cfnSubPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// resolve resolves the value into a string, with Ref and Fn::Sub of the parameters, and Fn::Join.
+func (t *cfnTemplate) resolve(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, int, float64:
+		return fmt.Sprint(v), true
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return "", false
+		}
+		if ref, ok := v["Ref"].(string); ok {
+			value, ok := t.parameters[ref]
+			return value, ok
+		}
+		if sub, ok := v["Fn::Sub"]; ok {
+			return t.sub(sub)
+		}
+		if join, ok := v["Fn::Join"].([]interface{}); ok && len(join) == 2 {
+			delimiter, ok := join[0].(string)
+			items, ok2 := join[1].([]interface{})
+			if !ok || !ok2 {
+				return "", false
+			}
+			parts := make([]string, 0, len(items))
+			for _, item := range items {
+				part, ok := t.resolve(item)
+				if !ok {
+					return "", false
+				}
+				parts = append(parts, part)
+			}
+			return strings.Join(parts, delimiter), true
+		}
+	}
+	return "", false
+}
+
+func (t *cfnTemplate) sub(v interface{}) (string, bool) {
+	var (
+		text string
+		vars = make(map[string]string)
+	)
+	switch v := v.(type) {
+	case string:
+		text = v
+	case []interface{}:
+		if len(v) != 2 {
+			return "", false
+		}
+		var ok bool
+		if text, ok = v[0].(string); !ok {
+			return "", false
+		}
+		m, _ := v[1].(map[string]interface{})
+		for name, value := range m {
+			resolved, ok := t.resolve(value)
+			if !ok {
+				return "", false
+			}
+			vars[name] = resolved
+		}
+	default:
+		return "", false
+	}
+	resolved := true
+	result := cfnSubPattern.ReplaceAllStringFunc(text, func(m string) string {
+		name := m[2 : len(m)-1]
+		if strings.HasPrefix(name, "!") {
+			return "${" + name[1:] + "}"
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if value, ok := t.parameters[name]; ok {
+			return value
+		}
+		resolved = false
+		return m
+	})
+	return result, resolved
+}
+
+// reference returns the resolved value, or the logical ID and the attribute for the references to the resources.
+func (t *cfnTemplate) reference(v interface{}) string {
+	if s, ok := t.resolve(v); ok {
+		return s
+	}
+	m, _ := v.(map[string]interface{})
+	if ref, ok := m["Ref"].(string); ok {
+		return ref
+	}
+	switch attr := m["Fn::GetAtt"].(type) {
+	case []interface{}:
+		parts := make([]string, 0, len(attr))
+		for _, part := range attr {
+			parts = append(parts, fmt.Sprint(part))
+		}
+		return strings.Join(parts, ".")
+	case string:
+		return attr
+	}
+	return ""
+}
+
+type nodePair struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingPairs returns the pairs of the mapping node in the order of the document.
+func mappingPairs(n *yaml.Node) []nodePair {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	pairs := make([]nodePair, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, nodePair{key: n.Content[i].Value, value: n.Content[i+1]})
+	}
+	return pairs
+}
+
+func mappingValue(n *yaml.Node, key string) (*yaml.Node, bool) {
+	for _, kv := range mappingPairs(n) {
+		if kv.key == key {
+			return kv.value, true
+		}
+	}
+	return nil, false
+}
+
+// cfnNodeValue converts the node into the value, the short form of the intrinsic functions such as !Ref is converted into the full form.
+func cfnNodeValue(n *yaml.Node) interface{} {
+	var v interface{}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return cfnNodeValue(n.Content[0])
+	case yaml.AliasNode:
+		return cfnNodeValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for _, kv := range mappingPairs(n) {
+			m[kv.key] = cfnNodeValue(kv.value)
+		}
+		v = m
+	case yaml.SequenceNode:
+		s := make([]interface{}, 0, len(n.Content))
+		for _, item := range n.Content {
+			s = append(s, cfnNodeValue(item))
+		}
+		v = s
+	default:
+		v = cfnScalarValue(n)
+	}
+	if !strings.HasPrefix(n.Tag, "!") || strings.HasPrefix(n.Tag, "!!") {
+		return v
+	}
+	name := strings.TrimPrefix(n.Tag, "!")
+	switch name {
+	case "Ref", "Condition":
+		return map[string]interface{}{name: v}
+	case "GetAtt":
+		if s, ok := v.(string); ok {
+			parts := strings.SplitN(s, ".", 2)
+			items := make([]interface{}, 0, len(parts))
+			for _, part := range parts {
+				items = append(items, part)
+			}
+			v = items
+		}
+	}
+	return map[string]interface{}{"Fn::" + name: v}
+}
+
+func cfnScalarValue(n *yaml.Node) interface{} {
+	switch n.ShortTag() {
+	case "!!bool":
+		b, err := strconv.ParseBool(n.Value)
+		if err == nil {
+			return b
+		}
+	case "!!int":
+		i, err := strconv.Atoi(n.Value)
+		if err == nil {
+			return i
+		}
+	case "!!float":
+		f, err := strconv.ParseFloat(n.Value, 64)
+		if err == nil {
+			return f
+		}
+	case "!!null":
+		return nil
+	}
+	return n.Value
+}
@@ -0,0 +1,161 @@
+package rules2cron_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+const testCloudFormationYAML = `AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  Hour:
+    Type: String
+    Default: "18"
+  Env:
+    Type: String
+    Default: dev
+Resources:
+  DailyRule:
+    Type: AWS::Events::Rule
+    Properties:
+      Description: !Sub "daily rule of ${Env}"
+      ScheduleExpression: !Sub "cron(0 ${Hour} * * ? *)"
+      Targets:
+        - Id: function
+          Arn: !GetAtt BatchFunction.Arn
+  PatternRule:
+    Type: AWS::Events::Rule
+    Properties:
+      EventPattern:
+        source: ["aws.ec2"]
+  RateRule:
+    Type: AWS::Events::Rule
+    Properties:
+      EventBusName: custom
+      State: DISABLED
+      ScheduleExpression:
+        Ref: Rate
+  Schedule:
+    Type: AWS::Scheduler::Schedule
+    Properties:
+      Name: !Sub "nightly-${Env}"
+      GroupName: batch
+      ScheduleExpression: cron(0 9 * * ? *)
+      ScheduleExpressionTimezone: Asia/Tokyo
+      FlexibleTimeWindow:
+        Mode: "OFF"
+      Target:
+        Arn: !Ref BatchFunction
+        RoleArn: arn:aws:iam::123456789012:role/scheduler
+  BatchFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Events:
+        Hourly:
+          Type: Schedule
+          Properties:
+            Schedule: rate(1 hour)
+            Enabled: false
+        Api:
+          Type: Api
+          Properties:
+            Path: /
+            Method: get
+        Weekly:
+          Type: ScheduleV2
+          Properties:
+            ScheduleExpression: !Join ["", ["cron(0 10 ? * MON *)"]]
+            ScheduleExpressionTimezone: America/New_York
+`
+
+func TestCloudFormationSource(t *testing.T) {
+	src := rules2cron.NewCloudFormationSource(strings.NewReader(testCloudFormationYAML), map[string]string{
+		"Rate": "rate(5 minutes)",
+		"Env":  "prod",
+	})
+	rules := make([]*rules2cron.Rule, 0)
+	err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+		rules = append(rules, rule)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 5)
+	require.Equal(t, &rules2cron.Rule{
+		Name:               "DailyRule",
+		Unnamed:            true,
+		State:              "ENABLED",
+		ScheduleExpression: "cron(0 18 * * ? *)",
+		Description:        "daily rule of prod",
+		EventBusName:       "default",
+		TimeZone:           time.UTC,
+		Targets:            []*rules2cron.Target{{ID: "function", Arn: "BatchFunction.Arn"}},
+	}, rules[0])
+	require.Equal(t, "RateRule", rules[1].Name)
+	require.Equal(t, "rate(5 minutes)", rules[1].ScheduleExpression)
+	require.Equal(t, "DISABLED", rules[1].State)
+	require.Equal(t, "custom", rules[1].EventBusName)
+	require.Equal(t, "Schedule", rules[2].Name)
+	require.Equal(t, "batch/nightly-prod", rules[2].DeployedName)
+	require.Equal(t, "Asia/Tokyo", rules[2].TimeZone.String())
+	require.Equal(t, []*rules2cron.Target{{
+		Arn:     "BatchFunction",
+		RoleArn: "arn:aws:iam::123456789012:role/scheduler",
+	}}, rules[2].Targets)
+	require.Equal(t, "BatchFunctionHourly", rules[3].Name)
+	require.Equal(t, "DISABLED", rules[3].State)
+	require.Equal(t, "BatchFunctionWeekly", rules[4].Name)
+	require.Equal(t, "cron(0 10 ? * MON *)", rules[4].ScheduleExpression)
+	require.Equal(t, "America/New_York", rules[4].TimeZone.String())
+}
+
+func TestCloudFormationSourceJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		expected []string
+	}{
+		{
+			name: "parameter default",
+			template: `{
+  "Parameters": {"Expression": {"Type": "String", "Default": "rate(1 day)"}},
+  "Resources": {
+    "Rule": {"Type": "AWS::Events::Rule", "Properties": {"ScheduleExpression": {"Ref": "Expression"}}}
+  }
+}`,
+			expected: []string{"Rule\trate(1 day)"},
+		},
+		{
+			name: "unresolved",
+			template: `{
+  "Resources": {
+    "Rule": {"Type": "AWS::Events::Rule", "Properties": {"ScheduleExpression": {"Fn::Sub": "cron(0 ${Unknown} * * ? *)"}}},
+    "Escaped": {"Type": "AWS::Events::Rule", "Properties": {"ScheduleExpression": {"Fn::Sub": ["rate(${N} ${!unit})", {"N": "1"}]}}}
+  }
+}`,
+			expected: []string{"Escaped\trate(1 ${unit})"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := rules2cron.NewCloudFormationSource(strings.NewReader(c.template), nil)
+			actual := make([]string, 0)
+			err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+				actual = append(actual, rule.Name+"\t"+rule.ScheduleExpression)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestCloudFormationSourceInvalid(t *testing.T) {
+	src := rules2cron.NewCloudFormationSource(strings.NewReader(`- not a template`), nil)
+	err := src.Rules(context.Background(), func(*rules2cron.Rule) error { return nil })
+	require.EqualError(t, err, "read template: must be a mapping")
+}
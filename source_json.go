@@ -0,0 +1,103 @@
+package rules2cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// JSONSource is the source of the rules dumped as JSON, such as `aws events list-rules` and `aws events describe-rule`.
+// The JSON is a single page, concatenated pages or a stream of the rules.
+// `aws scheduler get-schedule` is also supported, evaluated in its ScheduleExpressionTimezone.
+type JSONSource struct {
+	r io.Reader
+}
+
+// NewJSONSource returns the source which reads the JSON from r.
+func NewJSONSource(r io.Reader) *JSONSource {
+	return &JSONSource{r: r}
+}
+
+// jsonRule is a rule of ListRules, DescribeRule or GetSchedule output.
+type jsonRule struct {
+	Name                       string
+	Arn                        string
+	State                      string
+	Description                string
+	EventBusName               string
+	GroupName                  string
+	ScheduleExpression         string
+	ScheduleExpressionTimezone string
+	Target                     *struct {
+		Arn     string
+		RoleArn string
+	}
+}
+
+// jsonDocument is a JSON value of the input, a page of ListRules output or a rule.
+type jsonDocument struct {
+	Rules []*jsonRule
+	jsonRule
+}
+
+// Rules calls fn for each scheduled rule in the JSON.
+func (src *JSONSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	dec := json.NewDecoder(src.r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var doc jsonDocument
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read rules: %w", err)
+		}
+		rules := doc.Rules
+		if rules == nil {
+			rules = []*jsonRule{&doc.jsonRule}
+		}
+		for _, r := range rules {
+			if r.ScheduleExpression == "" {
+				log.Printf("[debug] rule %s is not scheduled rule, skip", r.Name)
+				continue
+			}
+			rule, err := r.rule()
+			if err != nil {
+				log.Printf("[warn] rule %s: %s", r.Name, err.Error())
+				continue
+			}
+			if err := fn(rule); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *jsonRule) rule() (*Rule, error) {
+	loc, err := loadScheduleLocation(r.ScheduleExpressionTimezone)
+	if err != nil {
+		return nil, err
+	}
+	name := r.Name
+	if r.GroupName != "" {
+		name = scheduleName(&r.GroupName, &r.Name)
+	}
+	rule := &Rule{
+		Name:               name,
+		Arn:                r.Arn,
+		State:              r.State,
+		ScheduleExpression: r.ScheduleExpression,
+		Description:        r.Description,
+		EventBusName:       r.EventBusName,
+		TimeZone:           loc,
+	}
+	if r.Target != nil {
+		rule.Targets = []*Target{{Arn: r.Target.Arn, RoleArn: r.Target.RoleArn}}
+	}
+	return rule, nil
+}
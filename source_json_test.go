@@ -0,0 +1,93 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+const testRulesJSON = `{
+  "Rules": [
+    {"Name": "daily", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/daily", "EventBusName": "default", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"},
+    {"Name": "pattern", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/pattern", "EventBusName": "default", "State": "ENABLED", "EventPattern": "{}"}
+  ],
+  "NextToken": "token"
+}
+{
+  "Rules": [
+    {"Name": "disabled", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/disabled", "EventBusName": "default", "State": "DISABLED", "ScheduleExpression": "rate(5 minutes)"}
+  ]
+}
+{"Name": "described", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/custom/described", "EventBusName": "custom", "State": "ENABLED", "Description": "described rule", "ScheduleExpression": "rate(1 hour)"}
+{"Name": "schedule", "GroupName": "batch", "Arn": "arn:aws:scheduler:ap-northeast-1:123456789012:schedule/batch/schedule", "State": "ENABLED", "ScheduleExpression": "cron(0 9 * * ? *)", "ScheduleExpressionTimezone": "Asia/Tokyo", "Target": {"Arn": "arn:aws:lambda:ap-northeast-1:123456789012:function:batch", "RoleArn": "arn:aws:iam::123456789012:role/scheduler"}}
+`
+
+func TestJSONSource(t *testing.T) {
+	src := rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+	rules := make([]*rules2cron.Rule, 0)
+	err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+		rules = append(rules, rule)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+	require.Equal(t, &rules2cron.Rule{
+		Name:               "daily",
+		Arn:                "arn:aws:events:ap-northeast-1:123456789012:rule/daily",
+		State:              "ENABLED",
+		ScheduleExpression: "cron(0 18 * * ? *)",
+		EventBusName:       "default",
+		TimeZone:           time.UTC,
+	}, rules[0])
+	require.Equal(t, "disabled", rules[1].Name)
+	require.Equal(t, "custom", rules[2].EventBusName)
+	require.Equal(t, "described rule", rules[2].Description)
+	require.Equal(t, "batch/schedule", rules[3].Name)
+	require.Equal(t, "Asia/Tokyo", rules[3].TimeZone.String())
+	require.Equal(t, []*rules2cron.Target{{
+		Arn:     "arn:aws:lambda:ap-northeast-1:123456789012:function:batch",
+		RoleArn: "arn:aws:iam::123456789012:role/scheduler",
+	}}, rules[3].Targets)
+}
+
+func TestJSONSourceInvalid(t *testing.T) {
+	src := rules2cron.NewJSONSource(strings.NewReader(`{"Rules": [`))
+	err := src.Rules(context.Background(), func(*rules2cron.Rule) error { return nil })
+	require.EqualError(t, err, "read rules: unexpected EOF")
+}
+
+func TestAppWithJSONSource(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      Must(time.LoadLocation("Asia/Tokyo")),
+	}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Equal(t, "0 3 * * *\tdaily\n"+
+		"0 * * * *\tcustom/described\n"+
+		"0 9 * * *\tbatch/schedule\n", buf.String())
+}
+
+func TestAppWithTargets(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(testRulesJSON))
+		o.Filter = rules2cron.RuleFilter{NamePrefix: "schedule"}
+		o.Formatter = Must(rules2cron.NewFormatter("jsonl"))
+		o.WithTargets = true
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, app.RunWithContext(context.Background(), &buf, false))
+	require.Contains(t, buf.String(), `"targets":[{"arn":"arn:aws:lambda:ap-northeast-1:123456789012:function:batch","role_arn":"arn:aws:iam::123456789012:role/scheduler"}]`)
+}
@@ -0,0 +1,148 @@
+package rules2cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// TerraformSource is the source of the schedules in the plan or the state of Terraform, the output of `terraform show -json`.
+// The rules are aws_cloudwatch_event_rule and aws_scheduler_schedule resources, named with the address of the resource,
+// such as module.batch.aws_cloudwatch_event_rule.daily["prod"], which includes the module path and the index of count or for_each.
+// The planned values are read for the plan, the values which are unknown until apply are skipped.
+type TerraformSource struct {
+	r io.Reader
+}
+
+// NewTerraformSource returns the source which reads the JSON of `terraform show -json` from r.
+func NewTerraformSource(r io.Reader) *TerraformSource {
+	return &TerraformSource{r: r}
+}
+
+// terraformDocument is the plan or the state of `terraform show -json`.
+type terraformDocument struct {
+	Values        *terraformValues `json:"values"`
+	PlannedValues *terraformValues `json:"planned_values"`
+}
+
+type terraformValues struct {
+	RootModule *terraformModule `json:"root_module"`
+}
+
+type terraformModule struct {
+	Resources    []*terraformResource `json:"resources"`
+	ChildModules []*terraformModule   `json:"child_modules"`
+}
+
+type terraformResource struct {
+	Address string                  `json:"address"`
+	Mode    string                  `json:"mode"`
+	Type    string                  `json:"type"`
+	Values  terraformResourceValues `json:"values"`
+}
+
+// terraformResourceValues is the attributes of aws_cloudwatch_event_rule and aws_scheduler_schedule.
+type terraformResourceValues struct {
+	Name                       string `json:"name"`
+	GroupName                  string `json:"group_name"`
+	Arn                        string `json:"arn"`
+	Description                string `json:"description"`
+	EventBusName               string `json:"event_bus_name"`
+	IsEnabled                  *bool  `json:"is_enabled"`
+	State                      string `json:"state"`
+	ScheduleExpression         string `json:"schedule_expression"`
+	ScheduleExpressionTimezone string `json:"schedule_expression_timezone"`
+	Target                     []struct {
+		Arn     string `json:"arn"`
+		RoleArn string `json:"role_arn"`
+	} `json:"target"`
+}
+
+// Rules calls fn for each scheduled resource in the plan or the state.
+func (src *TerraformSource) Rules(ctx context.Context, fn func(*Rule) error) error {
+	var doc terraformDocument
+	if err := json.NewDecoder(src.r).Decode(&doc); err != nil {
+		return fmt.Errorf("read terraform: %w", err)
+	}
+	values := doc.PlannedValues
+	if values == nil {
+		values = doc.Values
+	}
+	if values == nil || values.RootModule == nil {
+		return nil
+	}
+	return eachTerraformResource(ctx, values.RootModule, func(r *terraformResource) error {
+		if r.Mode != "managed" {
+			return nil
+		}
+		if r.Type != "aws_cloudwatch_event_rule" && r.Type != "aws_scheduler_schedule" {
+			return nil
+		}
+		if r.Values.ScheduleExpression == "" {
+			log.Printf("[debug] resource %s has no known schedule expression, skip", r.Address)
+			return nil
+		}
+		rule, err := r.rule()
+		if err != nil {
+			log.Printf("[warn] resource %s: %s", r.Address, err.Error())
+			return nil
+		}
+		return fn(rule)
+	})
+}
+
+func eachTerraformResource(ctx context.Context, m *terraformModule, fn func(*terraformResource) error) error {
+	for _, r := range m.Resources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	for _, child := range m.ChildModules {
+		if err := eachTerraformResource(ctx, child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *terraformResource) rule() (*Rule, error) {
+	v := r.Values
+	loc, err := loadScheduleLocation(v.ScheduleExpressionTimezone)
+	if err != nil {
+		return nil, err
+	}
+	rule := &Rule{
+		Name:               r.Address,
+		DeployedName:       scheduleName(&v.GroupName, &v.Name),
+		Unnamed:            v.Name == "",
+		Arn:                v.Arn,
+		State:              v.State,
+		ScheduleExpression: v.ScheduleExpression,
+		Description:        v.Description,
+		TimeZone:           loc,
+	}
+	if rule.Unnamed {
+		rule.DeployedName = ""
+	}
+	if rule.State == "" {
+		rule.State = "ENABLED"
+		if v.IsEnabled != nil && !*v.IsEnabled {
+			rule.State = "DISABLED"
+		}
+	}
+	if r.Type == "aws_cloudwatch_event_rule" {
+		rule.EventBusName = v.EventBusName
+		if rule.EventBusName == "" {
+			rule.EventBusName = "default"
+		}
+	}
+	for _, target := range v.Target {
+		rule.Targets = append(rule.Targets, &Target{Arn: target.Arn, RoleArn: target.RoleArn})
+	}
+	return rule, nil
+}
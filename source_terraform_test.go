@@ -0,0 +1,123 @@
+package rules2cron_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+const testTerraformPlanJSON = `{
+  "format_version": "1.1",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_cloudwatch_event_rule.daily",
+          "mode": "managed",
+          "type": "aws_cloudwatch_event_rule",
+          "name": "daily",
+          "values": {"name": "daily", "description": "daily rule", "schedule_expression": "cron(0 18 * * ? *)", "is_enabled": true}
+        },
+        {
+          "address": "aws_cloudwatch_event_rule.pattern",
+          "mode": "managed",
+          "type": "aws_cloudwatch_event_rule",
+          "name": "pattern",
+          "values": {"name": "pattern", "event_pattern": "{}", "schedule_expression": ""}
+        },
+        {
+          "address": "data.aws_cloudwatch_event_rule.existing",
+          "mode": "data",
+          "type": "aws_cloudwatch_event_rule",
+          "name": "existing",
+          "values": {"schedule_expression": "rate(1 day)"}
+        }
+      ],
+      "child_modules": [
+        {
+          "address": "module.batch",
+          "resources": [
+            {
+              "address": "module.batch.aws_cloudwatch_event_rule.this[0]",
+              "mode": "managed",
+              "type": "aws_cloudwatch_event_rule",
+              "name": "this",
+              "index": 0,
+              "values": {"event_bus_name": "custom", "schedule_expression": "rate(5 minutes)", "is_enabled": false}
+            },
+            {
+              "address": "module.batch.aws_scheduler_schedule.this[\"prod\"]",
+              "mode": "managed",
+              "type": "aws_scheduler_schedule",
+              "name": "this",
+              "index": "prod",
+              "values": {
+                "name": "prod",
+                "group_name": "default",
+                "state": "ENABLED",
+                "schedule_expression": "cron(0 9 * * ? *)",
+                "schedule_expression_timezone": "Asia/Tokyo",
+                "target": [{"arn": "arn:aws:lambda:ap-northeast-1:123456789012:function:batch", "role_arn": "arn:aws:iam::123456789012:role/scheduler"}]
+              }
+            }
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+func TestTerraformSource(t *testing.T) {
+	src := rules2cron.NewTerraformSource(strings.NewReader(testTerraformPlanJSON))
+	rules := make([]*rules2cron.Rule, 0)
+	err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+		rules = append(rules, rule)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	require.Equal(t, &rules2cron.Rule{
+		Name:               "aws_cloudwatch_event_rule.daily",
+		DeployedName:       "daily",
+		State:              "ENABLED",
+		ScheduleExpression: "cron(0 18 * * ? *)",
+		Description:        "daily rule",
+		EventBusName:       "default",
+		TimeZone:           time.UTC,
+	}, rules[0])
+	require.Equal(t, "module.batch.aws_cloudwatch_event_rule.this[0]", rules[1].Name)
+	require.Equal(t, "DISABLED", rules[1].State)
+	require.Equal(t, "custom", rules[1].EventBusName)
+	require.Equal(t, `module.batch.aws_scheduler_schedule.this["prod"]`, rules[2].Name)
+	require.Equal(t, "prod", rules[2].DeployedName)
+	require.Equal(t, "Asia/Tokyo", rules[2].TimeZone.String())
+	require.Equal(t, []*rules2cron.Target{{
+		Arn:     "arn:aws:lambda:ap-northeast-1:123456789012:function:batch",
+		RoleArn: "arn:aws:iam::123456789012:role/scheduler",
+	}}, rules[2].Targets)
+}
+
+func TestTerraformSourceState(t *testing.T) {
+	state := `{"format_version": "1.0", "values": {"root_module": {"resources": [
+  {"address": "aws_cloudwatch_event_rule.daily", "mode": "managed", "type": "aws_cloudwatch_event_rule", "name": "daily",
+   "values": {"arn": "arn:aws:events:ap-northeast-1:123456789012:rule/daily", "event_bus_name": "default", "state": "ENABLED", "schedule_expression": "rate(1 hour)"}}
+]}}}`
+	src := rules2cron.NewTerraformSource(strings.NewReader(state))
+	names := make([]string, 0)
+	err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+		names = append(names, rule.Name+"\t"+rule.Arn)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"aws_cloudwatch_event_rule.daily\tarn:aws:events:ap-northeast-1:123456789012:rule/daily"}, names)
+}
+
+func TestTerraformSourceInvalid(t *testing.T) {
+	src := rules2cron.NewTerraformSource(strings.NewReader(`{"planned_values": `))
+	err := src.Rules(context.Background(), func(*rules2cron.Rule) error { return nil })
+	require.EqualError(t, err, "read terraform: unexpected EOF")
+}
@@ -0,0 +1,293 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubAWSConfig returns the config to call the stub server of EventBridge and EventBridge Scheduler.
+// The rules are listed by the event bus name and NamePrefix, and the event buses are paginated by one.
+// Tags of the rule is returned by ListTagsForResource of the ARN, and Targets by ListTargetsByRule.
+func newStubAWSConfig(t *testing.T, rules map[string][]map[string]interface{}) aws.Config {
+	t.Helper()
+	buses := make([]string, 0, len(rules))
+	for name := range rules {
+		buses = append(buses, name)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&input)
+		output := map[string]interface{}{}
+		switch r.Header.Get("X-Amz-Target") {
+		case "AWSEvents.ListEventBuses":
+			i := 0
+			if token, ok := input["NextToken"].(string); ok {
+				for j, name := range buses {
+					if name == token {
+						i = j
+					}
+				}
+			}
+			output["EventBuses"] = []map[string]string{{"Name": buses[i]}}
+			if i+1 < len(buses) {
+				output["NextToken"] = buses[i+1]
+			}
+		case "AWSEvents.ListRules":
+			name, _ := input["EventBusName"].(string)
+			prefix, _ := input["NamePrefix"].(string)
+			listed := make([]map[string]interface{}, 0)
+			for _, rule := range rules[name] {
+				if strings.HasPrefix(rule["Name"].(string), prefix) {
+					listed = append(listed, rule)
+				}
+			}
+			output["Rules"] = listed
+		case "AWSEvents.ListTargetsByRule":
+			output["Targets"] = []interface{}{}
+			for _, rule := range rules[input["EventBusName"].(string)] {
+				if targets, ok := rule["Targets"]; ok && rule["Name"] == input["Rule"] {
+					output["Targets"] = targets
+				}
+			}
+		case "AWSEvents.ListTagsForResource":
+			tags := make([]map[string]string, 0)
+			for _, busRules := range rules {
+				for _, rule := range busRules {
+					if rule["Arn"] != input["ResourceARN"] {
+						continue
+					}
+					ruleTags, _ := rule["Tags"].(map[string]string)
+					for key, value := range ruleTags {
+						tags = append(tags, map[string]string{"Key": key, "Value": value})
+					}
+				}
+			}
+			output["Tags"] = tags
+		default:
+			if !strings.HasPrefix(r.URL.Path, "/schedules") {
+				http.Error(w, "unknown operation", http.StatusBadRequest)
+				return
+			}
+			output["Schedules"] = []interface{}{}
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(output)
+	}))
+	t.Cleanup(server.Close)
+	return aws.Config{
+		Region: "ap-northeast-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+		EndpointResolverWithOptions: aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{URL: server.URL, SigningRegion: region}, nil
+		}),
+	}
+}
+
+func TestAWSSource(t *testing.T) {
+	awsCfg := newStubAWSConfig(t, map[string][]map[string]interface{}{
+		"default": {
+			{"Name": "daily", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/daily", "Tags": map[string]string{"team": "batch"}},
+		},
+		"custom": {
+			{"Name": "hourly", "State": "ENABLED", "ScheduleExpression": "rate(1 hour)", "EventBusName": "custom", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/custom/hourly", "Tags": map[string]string{"team": "web"}},
+			{"Name": "pattern", "State": "ENABLED", "EventPattern": "{}", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/custom/pattern"},
+		},
+	})
+	cases := []struct {
+		name          string
+		eventBusNames []string
+		namePrefix    string
+		tags          map[string]string
+		expected      []string
+	}{
+		{
+			name:     "all",
+			expected: []string{"custom\thourly", "default\tdaily"},
+		},
+		{
+			name:          "selected",
+			eventBusNames: []string{"default"},
+			expected:      []string{"default\tdaily"},
+		},
+		{
+			name:       "name prefix",
+			namePrefix: "hour",
+			expected:   []string{"custom\thourly"},
+		},
+		{
+			name:     "tags",
+			tags:     map[string]string{"team": "batch"},
+			expected: []string{"default\tdaily"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := rules2cron.NewAWSSource(awsCfg, func(o *rules2cron.AWSSourceOptions) {
+				o.EventBusNames = c.eventBusNames
+				o.NamePrefix = c.namePrefix
+				o.Tags = c.tags
+			})
+			actual := make([]string, 0)
+			err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+				actual = append(actual, rule.EventBusName+"\t"+rule.Name)
+				return nil
+			})
+			require.NoError(t, err)
+			require.ElementsMatch(t, c.expected, actual)
+		})
+	}
+}
+
+func TestAWSSourceWithTargets(t *testing.T) {
+	awsCfg := newStubAWSConfig(t, map[string][]map[string]interface{}{
+		"default": {
+			{
+				"Name": "daily", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)", "Arn": "arn:aws:events:ap-northeast-1:123456789012:rule/daily",
+				"Targets": []map[string]interface{}{
+					{"Id": "task", "Arn": "arn:aws:ecs:ap-northeast-1:123456789012:cluster/batch", "RoleArn": "arn:aws:iam::123456789012:role/ecs-events",
+						"EcsParameters": map[string]string{"TaskDefinitionArn": "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/daily:3"}},
+					{"Id": "queue", "Arn": "arn:aws:sqs:ap-northeast-1:123456789012:daily",
+						"InputTransformer": map[string]interface{}{"InputPathsMap": map[string]string{"time": "$.time"}, "InputTemplate": `{"at": <time>}`}},
+				},
+			},
+		},
+	})
+	src := rules2cron.NewAWSSource(awsCfg, func(o *rules2cron.AWSSourceOptions) {
+		o.WithTargets = true
+	})
+	rules := make([]*rules2cron.Rule, 0)
+	err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+		rules = append(rules, rule)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, []*rules2cron.Target{
+		{
+			ID:                "task",
+			Arn:               "arn:aws:ecs:ap-northeast-1:123456789012:cluster/batch",
+			RoleArn:           "arn:aws:iam::123456789012:role/ecs-events",
+			TaskDefinitionArn: "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/daily:3",
+		},
+		{
+			ID:  "queue",
+			Arn: "arn:aws:sqs:ap-northeast-1:123456789012:daily",
+			InputTransformer: &rules2cron.InputTransformer{
+				InputPathsMap: map[string]string{"time": "$.time"},
+				InputTemplate: `{"at": <time>}`,
+			},
+		},
+	}, rules[0].Targets)
+}
+
+func TestAWSSourceScheduler(t *testing.T) {
+	cases := []struct {
+		name             string
+		withoutScheduler bool
+		status           int
+		errorType        string
+		expected         []string
+		expectedLog      string
+		expectedErr      string
+	}{
+		{
+			name:        "schedules",
+			expected:    []string{"daily", "batch/expired"},
+			expectedLog: "[warn] schedule batch/expired: expired at 2022-01-01T00:00:00Z, it does not fire any more",
+		},
+		{
+			name:             "without scheduler",
+			withoutScheduler: true,
+			expected:         []string{"daily"},
+		},
+		{
+			name:        "access denied",
+			status:      http.StatusForbidden,
+			errorType:   "AccessDeniedException",
+			expected:    []string{"daily"},
+			expectedLog: "[warn] the schedules of EventBridge Scheduler are skipped: ",
+		},
+		{
+			name:        "internal error",
+			status:      http.StatusInternalServerError,
+			errorType:   "InternalServerException",
+			expectedErr: "InternalServerException",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schedulerCalled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				output := map[string]interface{}{}
+				switch {
+				case r.Header.Get("X-Amz-Target") == "AWSEvents.ListRules":
+					output["Rules"] = []map[string]string{{"Name": "daily", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"}}
+				case r.URL.Path == "/schedules":
+					schedulerCalled = true
+					if c.status != 0 {
+						w.Header().Set("X-Amzn-ErrorType", c.errorType)
+						w.WriteHeader(c.status)
+						w.Write([]byte(`{"Message": "denied"}`))
+						return
+					}
+					output["Schedules"] = []map[string]string{{"Name": "expired", "GroupName": "batch", "Arn": "arn:aws:scheduler:ap-northeast-1:123456789012:schedule/batch/expired"}}
+				case r.URL.Path == "/schedules/expired":
+					output = map[string]interface{}{
+						"Name": "expired", "GroupName": "batch", "Arn": "arn:aws:scheduler:ap-northeast-1:123456789012:schedule/batch/expired",
+						"State": "ENABLED", "ScheduleExpression": "rate(1 hour)", "EndDate": 1640995200,
+					}
+				default:
+					http.Error(w, "unknown operation", http.StatusBadRequest)
+					return
+				}
+				w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+				json.NewEncoder(w).Encode(output)
+			}))
+			t.Cleanup(server.Close)
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			src := rules2cron.NewAWSSource(aws.Config{
+				Region: "ap-northeast-1",
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+				}),
+				RetryMaxAttempts: 1,
+				EndpointResolverWithOptions: aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+					return aws.Endpoint{URL: server.URL, SigningRegion: region}, nil
+				}),
+			}, func(o *rules2cron.AWSSourceOptions) {
+				o.EventBusNames = []string{"default"}
+				o.WithoutScheduler = c.withoutScheduler
+			})
+			actual := make([]string, 0)
+			err := src.Rules(context.Background(), func(rule *rules2cron.Rule) error {
+				actual = append(actual, rule.Name)
+				return nil
+			})
+			if c.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+			require.Equal(t, !c.withoutScheduler, schedulerCalled)
+			require.Contains(t, logs.String(), c.expectedLog)
+		})
+	}
+}
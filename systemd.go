@@ -0,0 +1,284 @@
+package rules2cron
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CalendarSpec is a calendar event of systemd.time(7), the value of OnCalendar= in the timer unit.
+// DayOfWeek is empty if any day of the week matches.
+type CalendarSpec struct {
+	DayOfWeek string
+	Year      string
+	Month     string
+	Day       string
+	Hour      string
+	Minute    string
+	Second    string
+	TimeZone  string
+}
+
+func (s *CalendarSpec) String() string {
+	spec := fmt.Sprintf("%s-%s-%s %s:%s:%s", s.Year, s.Month, s.Day, s.Hour, s.Minute, s.Second)
+	if s.DayOfWeek != "" {
+		spec = s.DayOfWeek + " " + spec
+	}
+	if s.TimeZone != "" {
+		spec += " " + s.TimeZone
+	}
+	return spec
+}
+
+// ConvertToCalendarSpec converts a parsed schedule expression evaluated in the given location into the calendar event of systemd.
+// The calendar event has the time zone suffix of the location, so TimeZone of the converter is not applied
+// and the daylight saving time is handled by systemd.
+func (c *Converter) ConvertToCalendarSpec(expr Expression, base *time.Location) (*CalendarSpec, error) {
+	if base == nil {
+		base = time.UTC
+	}
+	var (
+		spec *CalendarSpec
+		err  error
+	)
+	switch e := expr.(type) {
+	case *RateExpression:
+		spec, err = c.calendarSpecRate(e)
+	case *CronExpression:
+		spec, err = c.calendarSpecCron(e)
+	case *AtExpression:
+		t := e.Time(base)
+		spec = &CalendarSpec{
+			Year:   fmt.Sprintf("%04d", t.Year()),
+			Month:  fmt.Sprintf("%02d", int(t.Month())),
+			Day:    fmt.Sprintf("%02d", t.Day()),
+			Hour:   fmt.Sprintf("%02d", t.Hour()),
+			Minute: fmt.Sprintf("%02d", t.Minute()),
+			Second: fmt.Sprintf("%02d", t.Second()),
+		}
+	default:
+		err = newUnsupportedError(expr, "invalid format: unknown expression %T", expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	spec.TimeZone = base.String()
+	if c.Strict {
+		switch e := expr.(type) {
+		case *RateExpression:
+			err = checkRateLossless(e, "systemd")
+		case *CronExpression:
+			err = c.checkSpecialItemsLossless(e)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return spec, nil
+}
+
+func (c *Converter) calendarSpecRate(e *RateExpression) (*CalendarSpec, error) {
+	spec := &CalendarSpec{
+		Year:   "*",
+		Month:  "*",
+		Day:    "*",
+		Hour:   "00",
+		Minute: "00",
+		Second: "00",
+	}
+	every := func(start string) string {
+		if e.Value == 1 {
+			return "*"
+		}
+		return fmt.Sprintf("%s/%d", start, e.Value)
+	}
+	switch e.Unit {
+	case RateUnitMinutes:
+		spec.Hour = "*"
+		spec.Minute = every("00")
+	case RateUnitHours:
+		spec.Hour = every("00")
+	case RateUnitDays:
+		spec.Day = every("01")
+	default:
+		return nil, newUnsupportedError(e, "invalid format: unknown unit: %s", e.Unit)
+	}
+	return spec, nil
+}
+
+func (c *Converter) calendarSpecCron(e *CronExpression) (*CalendarSpec, error) {
+	dayOfMonth, err := c.resolveDayOfMonth(e)
+	if err != nil {
+		return nil, err
+	}
+	dayOfWeek := e.DayOfWeek.Items
+	if e.DayOfWeek.HasSpecialItem() {
+		dayOfMonth, err = c.resolveDayOfWeek(e)
+		if err != nil {
+			return nil, err
+		}
+		dayOfWeek = []*CronItem{{Kind: CronItemAll}}
+	}
+	month := e.Month.Items
+	if c.pinMonth {
+		month = []*CronItem{{Kind: CronItemValue, Start: int(c.ReferenceDate.Month())}}
+	}
+	return &CalendarSpec{
+		DayOfWeek: formatCalendarDayOfWeek(dayOfWeek),
+		Year:      formatCalendarField(CronFieldYear, e.Year.Items, "%04d"),
+		Month:     formatCalendarField(CronFieldMonth, month, "%02d"),
+		Day:       formatCalendarField(CronFieldDayOfMonth, dayOfMonth, "%02d"),
+		Hour:      formatCalendarField(CronFieldHours, e.Hours.Items, "%02d"),
+		Minute:    formatCalendarField(CronFieldMinutes, e.Minutes.Items, "%02d"),
+		Second:    "00",
+	}, nil
+}
+
+// formatCalendarField formats the plain items of the field in systemd.time(7).
+// Ranges with the step and the wrapping ranges are expanded, since systemd does not support them.
+func formatCalendarField(kind CronFieldKind, items []*CronItem, layout string) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.Kind == CronItemAll || item.Kind == CronItemNoSpecific:
+			if item.Step <= 1 {
+				return "*"
+			}
+			parts = append(parts, fmt.Sprintf(layout+"/%d", kind.Min(), item.Step))
+		case item.Kind == CronItemValue && item.Step > 0:
+			parts = append(parts, fmt.Sprintf(layout+"/%d", item.Start, item.Step))
+		case item.Kind == CronItemValue:
+			parts = append(parts, fmt.Sprintf(layout, item.Start))
+		case item.Kind == CronItemRange && item.Step <= 1 && item.Start <= item.End:
+			parts = append(parts, fmt.Sprintf(layout+".."+layout, item.Start, item.End))
+		default:
+			for v := kind.Min(); v <= kind.Max(); v++ {
+				if item.Match(kind, v) {
+					parts = append(parts, fmt.Sprintf(layout, v))
+				}
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+var calendarWeekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// formatCalendarDayOfWeek formats the plain items of the day-of-week, empty if any day of the week matches.
+func formatCalendarDayOfWeek(items []*CronItem) string {
+	matched := make([]bool, 7)
+	count := 0
+	for v := 1; v <= 7; v++ {
+		for _, item := range items {
+			if item.Match(CronFieldDayOfWeek, v) {
+				matched[v-1] = true
+				count++
+				break
+			}
+		}
+	}
+	if count == 7 {
+		return ""
+	}
+	parts := make([]string, 0, count)
+	for i := 0; i < 7; i++ {
+		if !matched[i] {
+			continue
+		}
+		j := i
+		for j+1 < 7 && matched[j+1] {
+			j++
+		}
+		if j == i {
+			parts = append(parts, calendarWeekdays[i])
+		} else {
+			parts = append(parts, calendarWeekdays[i]+".."+calendarWeekdays[j])
+		}
+		i = j
+	}
+	return strings.Join(parts, ",")
+}
+
+// SystemdUnit is a pair of the timer unit and the service unit of systemd for a rule.
+type SystemdUnit struct {
+	// Name is the unit name without the suffix.
+	Name       string
+	Rule       *Rule
+	OnCalendar *CalendarSpec
+	ExecStart  string
+}
+
+// NewSystemdUnit returns the unit of the rule, the unit name is the qualified name of the rule prefixed with prefix.
+// The characters which can not be used in the unit name are replaced with `-`.
+func NewSystemdUnit(rule *Rule, spec *CalendarSpec, prefix, execStart string) *SystemdUnit {
+	return &SystemdUnit{
+		Name:       systemdUnitName(prefix + rule.QualifiedName()),
+		Rule:       rule,
+		OnCalendar: spec,
+		ExecStart:  execStart,
+	}
+}
+
+func systemdUnitName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		case r == ':' || r == '_' || r == '.' || r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// Timer returns the content of the timer unit.
+func (u *SystemdUnit) Timer() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "[Unit]")
+	fmt.Fprintf(&b, "Description=%s %s\n", u.Rule.Name, u.Rule.ScheduleExpression)
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Timer]")
+	fmt.Fprintf(&b, "OnCalendar=%s\n", u.OnCalendar)
+	fmt.Fprintf(&b, "Unit=%s.service\n", u.Name)
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Install]")
+	fmt.Fprintln(&b, "WantedBy=timers.target")
+	return b.String()
+}
+
+// Service returns the content of the service unit.
+func (u *SystemdUnit) Service() string {
+	var b strings.Builder
+	if u.Rule.Arn != "" {
+		fmt.Fprintf(&b, "# %s\n", u.Rule.Arn)
+	}
+	fmt.Fprintln(&b, "[Unit]")
+	fmt.Fprintf(&b, "Description=%s\n", u.Rule.Name)
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Service]")
+	fmt.Fprintln(&b, "Type=oneshot")
+	fmt.Fprintf(&b, "ExecStart=%s\n", u.ExecStart)
+	return b.String()
+}
+
+// WriteFiles writes the timer unit and the service unit into the directory, and returns the written paths.
+func (u *SystemdUnit) WriteFiles(dir string) ([]string, error) {
+	files := []struct {
+		path    string
+		content string
+	}{
+		{path: filepath.Join(dir, u.Name+".timer"), content: u.Timer()},
+		{path: filepath.Join(dir, u.Name+".service"), content: u.Service()},
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, f.path)
+	}
+	return paths, nil
+}
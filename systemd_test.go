@@ -0,0 +1,143 @@
+package rules2cron_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToCalendarSpec(t *testing.T) {
+	cases := []struct {
+		scheduleExpression         string
+		scheduleExpressionTimezone string
+		expected                   string
+		expectedErrString          string
+	}{
+		{scheduleExpression: "rate(1 minute)", expected: "*-*-* *:*:00 UTC"},
+		{scheduleExpression: "rate(10 minutes)", expected: "*-*-* *:00/10:00 UTC"},
+		{scheduleExpression: "rate(2 hours)", expected: "*-*-* 00/2:00:00 UTC"},
+		{scheduleExpression: "rate(1 day)", expected: "*-*-* 00:00:00 UTC"},
+		{scheduleExpression: "cron(15 10 * * ? *)", expected: "*-*-* 10:15:00 UTC"},
+		{scheduleExpression: "cron(0/10 * ? * MON-FRI *)", expected: "Mon..Fri *-*-* *:00/10:00 UTC"},
+		{scheduleExpression: "cron(0 9-17/4 1,15 */2 ? 2022-2023)", expected: "2022..2023-01/2-01,15 09,13,17:00:00 UTC"},
+		{scheduleExpression: "cron(0 22-2 ? * FRI-MON *)", expected: "Sun..Mon,Fri..Sat *-*-* 00,01,02,22,23:00:00 UTC"},
+		{scheduleExpression: "cron(55-5 1 30-2 NOV-FEB ? *)", expected: "*-01,02,11,12-30..31,01..02 01:00,01,02,03,04,05,55,56,57,58,59:00 UTC"},
+		{scheduleExpression: "cron(0 3 * * ? *)", scheduleExpressionTimezone: "America/Los_Angeles", expected: "*-*-* 03:00:00 America/Los_Angeles"},
+		{scheduleExpression: "cron(0 3 L * ? *)", expected: "*-*-30 03:00:00 UTC"},
+		{scheduleExpression: "cron(0 3 15W * ? *)", expected: "*-*-15 03:00:00 UTC"},
+		{scheduleExpression: "cron(0 3 ? * 6#3 *)", expected: "*-*-17 03:00:00 UTC"},
+		{scheduleExpression: "cron(0 3 ? * 2L *)", expected: "*-*-27 03:00:00 UTC"},
+		{scheduleExpression: "at(2022-07-01T10:30:15)", scheduleExpressionTimezone: "Asia/Tokyo", expected: "2022-07-01 10:30:15 Asia/Tokyo"},
+		{scheduleExpression: "cron(0 3 ? * 3#5 *)", expectedErrString: "cannot be converted because the reference month has no 3#5"},
+	}
+	for _, c := range cases {
+		t.Run(c.scheduleExpression, func(t *testing.T) {
+			converter := &rules2cron.Converter{
+				ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				TimeZone:      time.UTC,
+			}
+			base := time.UTC
+			if c.scheduleExpressionTimezone != "" {
+				base = Must(time.LoadLocation(c.scheduleExpressionTimezone))
+			}
+			spec, err := converter.ConvertToCalendarSpec(Must(rules2cron.ParseExpression(c.scheduleExpression)), base)
+			if c.expectedErrString != "" {
+				require.EqualError(t, err, c.expectedErrString)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, spec.String())
+		})
+	}
+}
+
+func TestConvertToCalendarSpecStrict(t *testing.T) {
+	converter := &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+		Strict:        true,
+	}
+	_, err := converter.ConvertToCalendarSpec(Must(rules2cron.ParseExpression("rate(7 minutes)")), time.UTC)
+	require.EqualError(t, err, "lossy conversion: rate(7 minutes) restarts every hour in systemd")
+	spec, err := converter.ConvertToCalendarSpec(Must(rules2cron.ParseExpression("cron(0 3 * * ? 2022)")), time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, "2022-*-* 03:00:00 UTC", spec.String())
+}
+
+func TestSystemdUnit(t *testing.T) {
+	rule := &rules2cron.Rule{
+		Name:               "batch/daily report",
+		Arn:                "arn:aws:scheduler:ap-northeast-1:123456789012:schedule/batch/daily report",
+		ScheduleExpression: "cron(0 3 * * ? *)",
+		TimeZone:           time.UTC,
+	}
+	spec := &rules2cron.CalendarSpec{Year: "*", Month: "*", Day: "*", Hour: "03", Minute: "00", Second: "00", TimeZone: "UTC"}
+	unit := rules2cron.NewSystemdUnit(rule, spec, "rules2cron-", "/usr/local/bin/report")
+	require.Equal(t, "rules2cron-batch-daily-report", unit.Name)
+	require.Equal(t, `[Unit]
+Description=batch/daily report cron(0 3 * * ? *)
+
+[Timer]
+OnCalendar=*-*-* 03:00:00 UTC
+Unit=rules2cron-batch-daily-report.service
+
+[Install]
+WantedBy=timers.target
+`, unit.Timer())
+	require.Equal(t, `# arn:aws:scheduler:ap-northeast-1:123456789012:schedule/batch/daily report
+[Unit]
+Description=batch/daily report
+
+[Service]
+Type=oneshot
+ExecStart=/usr/local/bin/report
+`, unit.Service())
+
+	dir := t.TempDir()
+	paths, err := unit.WriteFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "rules2cron-batch-daily-report.timer"),
+		filepath.Join(dir, "rules2cron-batch-daily-report.service"),
+	}, paths)
+	content, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	require.Equal(t, unit.Timer(), string(content))
+}
+
+func TestSystemdUnitQualifiedName(t *testing.T) {
+	spec := &rules2cron.CalendarSpec{Year: "*", Month: "*", Day: "*", Hour: "03", Minute: "00", Second: "00", TimeZone: "UTC"}
+	cases := []struct {
+		rule     *rules2cron.Rule
+		expected string
+	}{
+		{rule: &rules2cron.Rule{Name: "daily", EventBusName: "default"}, expected: "rules2cron-daily"},
+		{rule: &rules2cron.Rule{Name: "daily", EventBusName: "custom"}, expected: "rules2cron-custom-daily"},
+		{rule: &rules2cron.Rule{Name: "daily", EventBusName: "default", Account: "123456789012", Region: "us-west-2"}, expected: "rules2cron-123456789012-us-west-2-daily"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expected, rules2cron.NewSystemdUnit(c.rule, spec, "rules2cron-", "/bin/true").Name)
+	}
+}
+
+func TestAppSystemdConflict(t *testing.T) {
+	app, err := rules2cron.New(context.Background(), &rules2cron.Converter{
+		ReferenceDate: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		TimeZone:      time.UTC,
+	}, func(o *rules2cron.AppOptions) {
+		o.Source = rules2cron.NewJSONSource(strings.NewReader(`
+{"Name": "daily report", "EventBusName": "default", "State": "ENABLED", "ScheduleExpression": "cron(0 18 * * ? *)"}
+{"Name": "daily-report", "EventBusName": "default", "State": "ENABLED", "ScheduleExpression": "cron(0 19 * * ? *)"}
+{"Name": "daily report", "EventBusName": "custom", "State": "ENABLED", "ScheduleExpression": "cron(0 20 * * ? *)"}
+`))
+	})
+	require.NoError(t, err)
+	err = app.RunSystemdWithContext(context.Background(), false, rules2cron.SystemdOptions{Dir: t.TempDir(), UnitPrefix: "rules2cron-"})
+	require.EqualError(t, err, "unit rules2cron-daily-report conflicts between rule daily report and daily-report")
+}
@@ -0,0 +1,193 @@
+package rules2cron
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+// TimelineOptions is the options for the HTML timeline.
+type TimelineOptions struct {
+	// Start and Duration is the period of the timeline.
+	Start    time.Time
+	Duration time.Duration
+	// TimeZone is the time zone to display, UTC if nil.
+	TimeZone *time.Location
+	Title    string
+}
+
+// Timeline is a self-contained HTML report of the fire times of the rules.
+type Timeline struct {
+	opts TimelineOptions
+	rows []*timelineRow
+}
+
+type timelineRow struct {
+	Rule  *Rule
+	Ticks []*timelineTick
+}
+
+type timelineTick struct {
+	Left float64
+	Time string
+}
+
+type timelineAxisLabel struct {
+	Left  float64
+	Label string
+}
+
+// NewTimeline returns the timeline of the period, 24 hours if the duration is zero.
+func NewTimeline(opts TimelineOptions) *Timeline {
+	if opts.Duration <= 0 {
+		opts.Duration = 24 * time.Hour
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	if opts.Title == "" {
+		opts.Title = "rules2cron"
+	}
+	return &Timeline{opts: opts}
+}
+
+// AddRule adds the row of the rule, the fire times are evaluated with the EventBridge semantics.
+func (t *Timeline) AddRule(rule *Rule) error {
+	expr, err := ParseExpression(rule.ScheduleExpression)
+	if err != nil {
+		return err
+	}
+	row := &timelineRow{Rule: rule}
+	for _, fireTime := range FireTimesBetween(expr, t.opts.Start, t.end(), rule.TimeZone) {
+		row.Ticks = append(row.Ticks, &timelineTick{
+			Left: t.left(fireTime),
+			Time: fireTime.In(t.opts.TimeZone).Format(time.RFC3339),
+		})
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+func (t *Timeline) end() time.Time {
+	return t.opts.Start.Add(t.opts.Duration)
+}
+
+// left returns the position of the time in percent.
+func (t *Timeline) left(at time.Time) float64 {
+	return float64(at.Sub(t.opts.Start)) / float64(t.opts.Duration) * 100
+}
+
+// timelineAxisSteps is the candidates of the interval of the axis labels.
+var timelineAxisSteps = []time.Duration{
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// axis returns the labels of the axis, at most 24 labels on the hour in the display time zone.
+func (t *Timeline) axis() []*timelineAxisLabel {
+	step := timelineAxisSteps[len(timelineAxisSteps)-1]
+	for _, s := range timelineAxisSteps {
+		if t.opts.Duration/s <= 24 {
+			step = s
+			break
+		}
+	}
+	layout := "15:04"
+	if step >= 24*time.Hour {
+		layout = "01/02"
+	}
+	start := t.opts.Start.In(t.opts.TimeZone)
+	at := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, t.opts.TimeZone)
+	if at.Before(start) {
+		at = at.Add(time.Hour)
+	}
+	labels := make([]*timelineAxisLabel, 0)
+	for ; at.Before(t.end()); at = at.Add(time.Hour) {
+		local := at.In(t.opts.TimeZone)
+		if step >= 24*time.Hour {
+			if local.Hour() != 0 || (step > 24*time.Hour && local.Weekday() != time.Monday) {
+				continue
+			}
+		} else if local.Hour()%int(step/time.Hour) != 0 {
+			continue
+		}
+		label := local.Format(layout)
+		if local.Hour() == 0 && step < 24*time.Hour {
+			label = local.Format("01/02 15:04")
+		}
+		labels = append(labels, &timelineAxisLabel{Left: t.left(at), Label: label})
+	}
+	return labels
+}
+
+// WriteHTML writes the timeline as a self-contained HTML.
+func (t *Timeline) WriteHTML(w io.Writer) error {
+	return timelineTemplate.Execute(w, map[string]interface{}{
+		"Title":    t.opts.Title,
+		"Start":    t.opts.Start.In(t.opts.TimeZone).Format(time.RFC3339),
+		"End":      t.end().In(t.opts.TimeZone).Format(time.RFC3339),
+		"TimeZone": t.opts.TimeZone.String(),
+		"Axis":     t.axis(),
+		"Rows":     t.rows,
+	})
+}
+
+var timelineTemplate = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 16px; color: #333; }
+h1 { font-size: 18px; }
+.timeline { border-top: 1px solid #ccc; }
+.row { display: flex; border-bottom: 1px solid #eee; }
+.row:hover { background: #f5f9ff; }
+.row.disabled { color: #aaa; }
+.label { width: 280px; flex-shrink: 0; padding: 4px 8px; position: relative; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.label .detail { display: none; position: absolute; left: 8px; top: 100%; z-index: 1; background: #fff; border: 1px solid #ccc; box-shadow: 0 2px 6px rgba(0,0,0,.2); padding: 8px; white-space: normal; width: 480px; color: #333; }
+.row:hover .label { overflow: visible; }
+.row:hover .label .detail { display: block; }
+.detail dt { font-weight: bold; }
+.detail dd { margin: 0 0 4px 0; word-break: break-all; }
+.bar { position: relative; flex-grow: 1; }
+.tick { position: absolute; top: 4px; bottom: 4px; width: 2px; background: #2f6fd6; }
+.row.disabled .tick { background: #ccc; }
+.axis { position: relative; height: 20px; margin-left: 296px; }
+.axis span { position: absolute; transform: translateX(-50%); color: #888; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>{{ .Start }} ~ {{ .End }} ({{ .TimeZone }})</p>
+<div class="axis">{{ range .Axis }}<span style="left: {{ printf "%.3f" .Left }}%">{{ .Label }}</span>{{ end }}</div>
+<div class="timeline">
+{{- range .Rows }}
+<div class="row{{ if eq .Rule.State "DISABLED" }} disabled{{ end }}">
+<div class="label">{{ .Rule.Name }}
+<dl class="detail">
+{{- with .Rule.Arn }}<dt>ARN</dt><dd>{{ . }}</dd>{{ end }}
+{{- with .Rule.EventBusName }}<dt>Event bus</dt><dd>{{ . }}</dd>{{ end }}
+{{- with .Rule.Account }}<dt>Account</dt><dd>{{ . }}</dd>{{ end }}
+{{- with .Rule.Region }}<dt>Region</dt><dd>{{ . }}</dd>{{ end }}
+{{- with .Rule.Description }}<dt>Description</dt><dd>{{ . }}</dd>{{ end }}
+<dt>Schedule expression</dt><dd>{{ .Rule.ScheduleExpression }}{{ with .Rule.TimeZone }} ({{ .String }}){{ end }}</dd>
+{{- with .Rule.State }}<dt>State</dt><dd>{{ . }}</dd>{{ end }}
+{{- with .Rule.Targets }}<dt>Targets</dt>{{ range . }}<dd>{{ .Arn }}
+{{- with .TaskDefinitionArn }}<br>task definition: {{ . }}{{ end }}
+{{- with .RoleArn }}<br>role: {{ . }}{{ end }}
+{{- if .InputTransformer }}<br>input template: {{ .InputTransformer.InputTemplate }}{{ else if .InputPath }}<br>input path: {{ .InputPath }}{{ else if .Input }}<br>input: {{ .Input }}{{ end }}</dd>{{ end }}{{ end }}
+<dt>Fire times</dt><dd>{{ len .Ticks }}</dd>
+</dl>
+</div>
+<div class="bar">{{ range .Ticks }}<span class="tick" style="left: {{ printf "%.3f" .Left }}%" title="{{ .Time }}"></span>{{ end }}</div>
+</div>
+{{- end }}
+</div>
+</body>
+</html>
+`))
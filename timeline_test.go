@@ -0,0 +1,58 @@
+package rules2cron_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mashiike/rules2cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	timeline := rules2cron.NewTimeline(rules2cron.TimelineOptions{
+		Start:    time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		Duration: 24 * time.Hour,
+		TimeZone: Must(time.LoadLocation("Asia/Tokyo")),
+		Title:    "schedules",
+	})
+	require.NoError(t, timeline.AddRule(&rules2cron.Rule{
+		Name:               "every-six-hours",
+		Arn:                "arn:aws:events:ap-northeast-1:123456789012:rule/every-six-hours",
+		State:              "ENABLED",
+		Description:        "<b>batch</b>",
+		ScheduleExpression: "cron(0 0/6 * * ? *)",
+		TimeZone:           time.UTC,
+		Targets: []*rules2cron.Target{
+			{Arn: "arn:aws:lambda:ap-northeast-1:123456789012:function:batch"},
+			{Arn: "arn:aws:sqs:ap-northeast-1:123456789012:batch", RoleArn: "arn:aws:iam::123456789012:role/events", InputTransformer: &rules2cron.InputTransformer{InputTemplate: "<time>"}},
+		},
+	}))
+	require.NoError(t, timeline.AddRule(&rules2cron.Rule{
+		Name:               "disabled",
+		State:              "DISABLED",
+		ScheduleExpression: "cron(30 12 * * ? *)",
+		TimeZone:           time.UTC,
+	}))
+	require.Error(t, timeline.AddRule(&rules2cron.Rule{
+		Name:               "invalid",
+		ScheduleExpression: "cron(0 0 * * *)",
+		TimeZone:           time.UTC,
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, timeline.WriteHTML(&buf))
+	html := buf.String()
+	require.Contains(t, html, "<title>schedules</title>")
+	require.Contains(t, html, "<p>2022-06-01T09:00:00&#43;09:00 ~ 2022-06-02T09:00:00&#43;09:00 (Asia/Tokyo)</p>")
+	require.Contains(t, html, `<span style="left: 62.500%">06/02 00:00</span>`)
+	require.Contains(t, html, `<span style="left: 87.500%">06:00</span>`)
+	require.Contains(t, html, `<dt>ARN</dt><dd>arn:aws:events:ap-northeast-1:123456789012:rule/every-six-hours</dd>`)
+	require.Contains(t, html, `<dt>Description</dt><dd>&lt;b&gt;batch&lt;/b&gt;</dd>`)
+	require.Contains(t, html, `<dt>Targets</dt><dd>arn:aws:lambda:ap-northeast-1:123456789012:function:batch</dd>`)
+	require.Contains(t, html, `<dd>arn:aws:sqs:ap-northeast-1:123456789012:batch<br>role: arn:aws:iam::123456789012:role/events<br>input template: &lt;time&gt;</dd>`)
+	require.Contains(t, html, `<dt>Schedule expression</dt><dd>cron(0 0/6 * * ? *) (UTC)</dd>`)
+	require.Contains(t, html, `<div class="bar"><span class="tick" style="left: 0.000%" title="2022-06-01T09:00:00&#43;09:00"></span><span class="tick" style="left: 25.000%" title="2022-06-01T15:00:00&#43;09:00"></span><span class="tick" style="left: 50.000%" title="2022-06-01T21:00:00&#43;09:00"></span><span class="tick" style="left: 75.000%" title="2022-06-02T03:00:00&#43;09:00"></span></div>`)
+	require.Contains(t, html, `<div class="row disabled">`)
+	require.NotContains(t, html, "invalid")
+}
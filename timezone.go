@@ -0,0 +1,404 @@
+package rules2cron
+
+import (
+	"sort"
+	"time"
+)
+
+// timeZoneOffset returns the difference of the UTC offset from base to the location at t, in seconds.
+func timeZoneOffset(t time.Time, base *time.Location, to *time.Location) int {
+	_, baseOffset := t.In(base).Zone()
+	_, toOffset := t.In(to).Zone()
+	return toOffset - baseOffset
+}
+
+// shiftTimeZone shifts the crontab fields by the offset in seconds.
+// The minutes are shifted for the time zones which are not whole hours, and carried over into the hours.
+func (c *Converter) shiftTimeZone(f *crontabFields, offset int) []*crontabFields {
+	offsetMinutes := floorDiv(offset, 60)
+	hours, minutes := floorDiv(offsetMinutes, 60), modulo(offsetMinutes, 60)
+	if minutes == 0 {
+		return c.shiftHours(f, hours)
+	}
+	if isAllItems(f.hour) && !f.isDaySensitive() {
+		// carrying over into the hours has no effect
+		shifted := f.clone()
+		shifted.minute = shiftCyclicItems(f.minute, minutes, 0, 59)
+		return c.shiftHours(shifted, hours)
+	}
+	ret := make([]*crontabFields, 0, 2)
+	for _, carried := range shiftItems(f.minute, minutes, 0, 59) {
+		shifted := f.clone()
+		shifted.minute = carried.items
+		ret = append(ret, c.shiftHours(shifted, hours+carried.carry)...)
+	}
+	return ret
+}
+
+// shiftHours shifts the crontab fields by hours, and carries over into the day-of-month, the day-of-week and the month.
+// The result is split into multiple fields when the shifted hours straddle midnight.
+func (c *Converter) shiftHours(f *crontabFields, hours int) []*crontabFields {
+	if hours == 0 {
+		return []*crontabFields{f}
+	}
+	if !f.isDaySensitive() {
+		shifted := f.clone()
+		shifted.hour = make([]*CronItem, 0, len(f.hour))
+		for _, item := range f.hour {
+			shifted.hour = append(shifted.hour, shiftWrappedItem(item, hours, 0, 23)...)
+		}
+		return []*crontabFields{shifted}
+	}
+	ret := make([]*crontabFields, 0, 2)
+	for _, carried := range shiftItems(f.hour, hours, 0, 23) {
+		shifted := f.clone()
+		shifted.hour = carried.items
+		ret = append(ret, c.shiftDays(shifted, carried.carry)...)
+	}
+	return ret
+}
+
+// shiftDays shifts the day fields by days.
+// When the month is restricted, the day-of-month is shifted in each group of the months which have the same number of days,
+// so that the day after the last day is the 1st of the next month, and the day before the 1st is the last day of the previous month.
+func (c *Converter) shiftDays(f *crontabFields, days int) []*crontabFields {
+	if days == 0 {
+		return []*crontabFields{f}
+	}
+	shifted := f.clone()
+	dayOfWeekRestricted := !isAllItems(f.dayOfWeek)
+	if dayOfWeekRestricted {
+		shifted.dayOfWeek = shiftDayOfWeekItems(f.dayOfWeek, days)
+	}
+	monthRestricted := !isAllItems(f.month)
+	if isAllItems(f.dayOfMonth) && (dayOfWeekRestricted || !monthRestricted) {
+		return []*crontabFields{shifted}
+	}
+	year, refMonth := c.ReferenceDate.Year(), c.ReferenceDate.Month()
+	if !monthRestricted {
+		// the days are resolved in the reference month if they depend on the month, such as L and W.
+		last := 31
+		if f.resolvedInMonth {
+			last = lastDayOfMonth(year, refMonth)
+		}
+		return c.shiftDaysOfMonth(shifted, days, last, lastDayOfMonth(year, refMonth-1))
+	}
+	months := itemValues(f.month, 1, 12)
+	// the numbers of the days of the month and the previous month which the carry depends on
+	keys := make([][2]int, 0, 2)
+	groups := make(map[[2]int][]bool)
+	for i, ok := range months {
+		if !ok {
+			continue
+		}
+		month := time.Month(i + 1)
+		key := [2]int{lastDayOfMonth(year, month), 0}
+		if days < 0 {
+			key[1] = lastDayOfMonth(year, month-1)
+			if c.lastDayItem {
+				key[1] = 31
+			}
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			groups[key] = make([]bool, 12)
+		}
+		groups[key][i] = true
+	}
+	ret := make([]*crontabFields, 0, len(keys))
+	for _, key := range keys {
+		g := shifted.clone()
+		g.month = compressValues(groups[key], 1, 12)
+		if len(keys) == 1 {
+			g.month = f.month
+		}
+		ret = append(ret, c.shiftDaysOfMonth(g, days, key[0], key[1])...)
+	}
+	return mergeMonths(ret)
+}
+
+// mergeMonths merges the fields which differ only in the month, such as L of the months carried from the groups of the different numbers of days.
+func mergeMonths(fields []*crontabFields) []*crontabFields {
+	ret := make([]*crontabFields, 0, len(fields))
+	merged := make(map[Schedule]*crontabFields)
+	for _, f := range fields {
+		key := *f.schedule()
+		key.Month = ""
+		m, ok := merged[key]
+		if !ok {
+			merged[key] = f
+			ret = append(ret, f)
+			continue
+		}
+		months := itemValues(m.month, 1, 12)
+		for i, ok := range itemValues(f.month, 1, 12) {
+			months[i] = months[i] || ok
+		}
+		m.month = compressValues(months, 1, 12)
+	}
+	return ret
+}
+
+// shiftDaysOfMonth shifts the day-of-month by days in the month which has last days, and the previous month has prevLast days.
+// The days after last are dropped since they do not exist, and the month is carried over with the days.
+func (c *Converter) shiftDaysOfMonth(f *crontabFields, days, last, prevLast int) []*crontabFields {
+	carried := make(map[int][]bool)
+	for i, ok := range itemValues(f.dayOfMonth, 1, 31) {
+		day := i + 1
+		if !ok || day > last {
+			continue
+		}
+		carry, shiftedDay := 0, day+days
+		switch {
+		case shiftedDay < 1:
+			carry, shiftedDay = -1, prevLast+shiftedDay
+		case shiftedDay > last:
+			carry, shiftedDay = 1, shiftedDay-last
+		}
+		if _, ok := carried[carry]; !ok {
+			carried[carry] = make([]bool, 31)
+		}
+		carried[carry][shiftedDay-1] = true
+	}
+	carries := make([]int, 0, len(carried))
+	for carry := range carried {
+		carries = append(carries, carry)
+	}
+	sort.Ints(carries)
+	ret := make([]*crontabFields, 0, len(carries))
+	for _, carry := range carries {
+		g := f.clone()
+		g.dayOfMonth = compressValues(carried[carry], 1, 31)
+		if carry < 0 && c.lastDayItem {
+			g.dayOfMonth = []*CronItem{{Kind: CronItemLast}}
+		}
+		if !isAllItems(f.month) && carry != 0 {
+			months := make([]*CronItem, 0, len(f.month))
+			for _, monthCarried := range shiftItems(f.month, carry, 1, 12) {
+				months = append(months, monthCarried.items...)
+			}
+			g.month = compressValues(itemValues(months, 1, 12), 1, 12)
+		}
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// monthEndCarriedDay returns the day whose carry by the offset in minutes depends on the number of days of the month, 0 if none.
+// minutes[i] is the minute i, hours[i] is the hour i and days[i] is the day i+1.
+// The 1st carried back to the last day of the previous month is exact with L when lastDayItem is set.
+func monthEndCarriedDay(minutes, hours, days []bool, offsetMinutes int, lastDayItem bool) int {
+	carries := make(map[int]bool)
+	for hour, hourOK := range hours {
+		for minute, minuteOK := range minutes {
+			if hourOK && minuteOK {
+				carries[floorDiv(hour*60+minute+offsetMinutes, 24*60)] = true
+			}
+		}
+	}
+	for i, ok := range days {
+		day := i + 1
+		if !ok {
+			continue
+		}
+		if (carries[1] && day >= 28) || (carries[-1] && (day >= 29 || (day == 1 && !lastDayItem))) {
+			return day
+		}
+	}
+	return 0
+}
+
+// shiftDayOfWeekItems shifts crontab's day-of-week items cyclically.
+func shiftDayOfWeekItems(items []*CronItem, days int) []*CronItem {
+	return shiftCyclicItems(items, days, 0, 6)
+}
+
+// shiftCyclicItems shifts the crontab items by delta cyclically within [min, max], ignoring the carry.
+func shiftCyclicItems(items []*CronItem, delta, min, max int) []*CronItem {
+	values := make([]bool, max-min+1)
+	for i, ok := range itemValues(items, min, max) {
+		if ok {
+			values[modulo(i+delta, max-min+1)] = true
+		}
+	}
+	return compressValues(values, min, max)
+}
+
+// itemValues returns the values which the crontab items mean, values[i] is min+i.
+func itemValues(items []*CronItem, min, max int) []bool {
+	values := make([]bool, max-min+1)
+	for _, item := range items {
+		start, end := crontabItemBounds(item, min, max)
+		step := item.Step
+		if step < 1 {
+			step = 1
+		}
+		for v := start; v <= end; v += step {
+			values[v-min] = true
+		}
+	}
+	return values
+}
+
+// compressValues returns the crontab items which mean the values, values[i] is min+i.
+// The values are compressed into a single item if they are an arithmetic sequence, otherwise into ranges.
+func compressValues(values []bool, min, max int) []*CronItem {
+	list := make([]int, 0, len(values))
+	for i, ok := range values {
+		if ok {
+			list = append(list, min+i)
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	if len(list) > 2 {
+		step := list[1] - list[0]
+		arithmetic := true
+		for i := 2; i < len(list); i++ {
+			if list[i]-list[i-1] != step {
+				arithmetic = false
+				break
+			}
+		}
+		if arithmetic {
+			return []*CronItem{newCrontabItem(list[0], list[len(list)-1], step, min, max)}
+		}
+	}
+	ret := make([]*CronItem, 0, len(list))
+	for i := 0; i < len(list); i++ {
+		j := i
+		for j+1 < len(list) && list[j+1] == list[j]+1 {
+			j++
+		}
+		ret = append(ret, newCrontabItem(list[i], list[j], 1, min, max))
+		i = j
+	}
+	return ret
+}
+
+// shiftWrappedItem shifts the crontab item by delta, ignoring the carry.
+// If the shifted item wraps around past max, it is split into ascending items, keeping the step from the original start.
+// e.g. 13-16 shifted by 9 hours is 22-23,0-1
+func shiftWrappedItem(item *CronItem, delta, min, max int) []*CronItem {
+	if delta == 0 || (item.Kind == CronItemAll && item.Step <= 1) {
+		return []*CronItem{item}
+	}
+	segments := shiftItem(item, delta, min, max)
+	carries := make([]int, 0, len(segments))
+	for carry := range segments {
+		carries = append(carries, carry)
+	}
+	sort.Ints(carries)
+	ret := make([]*CronItem, 0, len(segments))
+	for _, carry := range carries {
+		ret = append(ret, segments[carry])
+	}
+	if len(ret) > 1 {
+		// e.g. */3 shifted by 9 hours is still */3
+		if compressed := shiftCyclicItems([]*CronItem{item}, delta, min, max); len(compressed) == 1 {
+			return compressed
+		}
+	}
+	return ret
+}
+
+type carriedItems struct {
+	carry int
+	items []*CronItem
+}
+
+// shiftItems shifts the crontab items by delta within [min, max], and groups the shifted items by the carry over the field.
+func shiftItems(items []*CronItem, delta, min, max int) []*carriedItems {
+	groups := make(map[int]*carriedItems)
+	for _, item := range items {
+		for carry, shifted := range shiftItem(item, delta, min, max) {
+			if _, ok := groups[carry]; !ok {
+				groups[carry] = &carriedItems{carry: carry}
+			}
+			groups[carry].items = append(groups[carry].items, shifted)
+		}
+	}
+	ret := make([]*carriedItems, 0, len(groups))
+	for _, g := range groups {
+		ret = append(ret, g)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].carry < ret[j].carry
+	})
+	return ret
+}
+
+// shiftItem shifts the crontab item by delta, and splits the item by the carry over [min, max].
+func shiftItem(item *CronItem, delta, min, max int) map[int]*CronItem {
+	if delta == 0 {
+		return map[int]*CronItem{0: item}
+	}
+	lo, hi := crontabItemBounds(item, min, max)
+	step := item.Step
+	if step < 1 {
+		step = 1
+	}
+	width := max - min + 1
+	first := lo + delta
+	last := first + (hi-lo)/step*step
+	ret := make(map[int]*CronItem)
+	for carry := floorDiv(first-min, width); carry <= floorDiv(last-min, width); carry++ {
+		lower, upper := min+carry*width, max+carry*width
+		segmentFirst, segmentLast := first, last
+		if segmentFirst < lower {
+			segmentFirst = first + (lower-first+step-1)/step*step
+		}
+		if segmentLast > upper {
+			segmentLast = first + (upper-first)/step*step
+		}
+		if segmentFirst > segmentLast {
+			continue
+		}
+		ret[carry] = newCrontabItem(segmentFirst-carry*width, segmentLast-carry*width, step, min, max)
+	}
+	return ret
+}
+
+// crontabItemBounds returns the first and the last value of the crontab item in [min, max].
+func crontabItemBounds(item *CronItem, min, max int) (int, int) {
+	switch item.Kind {
+	case CronItemValue:
+		if item.Step > 0 {
+			return item.Start, max
+		}
+		return item.Start, item.Start
+	case CronItemRange:
+		return item.Start, item.End
+	default:
+		return min, max
+	}
+}
+
+// newCrontabItem returns the crontab item which means first, first+step, ... last.
+func newCrontabItem(first, last, step, min, max int) *CronItem {
+	switch {
+	case first == last:
+		return &CronItem{Kind: CronItemValue, Start: first}
+	case first == min && last+step > max:
+		if step == 1 {
+			return &CronItem{Kind: CronItemAll}
+		}
+		return &CronItem{Kind: CronItemAll, Step: step}
+	case step == 1:
+		return &CronItem{Kind: CronItemRange, Start: first, End: last}
+	default:
+		return &CronItem{Kind: CronItemRange, Start: first, End: last, Step: step}
+	}
+}
+
+func modulo(a, b int) int {
+	return (a%b + b) % b
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}